@@ -81,6 +81,11 @@ const (
 	CodeSQLUniqueConstraint
 	CodeSQLConflict
 	CodeSQLNoRowsAffected
+	CodeSQLForeignKeyViolation
+	CodeSQLNotNullViolation
+	CodeSQLDeadlock
+	CodeSQLMigration
+	CodeSQLMigrationLocked
 )
 
 // Cache Error
@@ -137,20 +142,25 @@ var ErrorMessage = AppMessage{
 	CodeJSONUnmarshalError: ErrMsgBadRequest,
 
 	// Code SQL
-	CodeSQL:                   ErrMsgInternalServerError,
-	CodeSQLInit:               ErrMsgInternalServerError,
-	CodeSQLBuilder:            ErrMsgInternalServerError,
-	CodeSQLTxBegin:            ErrMsgInternalServerError,
-	CodeSQLTxCommit:           ErrMsgInternalServerError,
-	CodeSQLTxRollback:         ErrMsgInternalServerError,
-	CodeSQLTxExec:             ErrMsgInternalServerError,
-	CodeSQLPrepareStmt:        ErrMsgInternalServerError,
-	CodeSQLRead:               ErrMsgInternalServerError,
-	CodeSQLRowScan:            ErrMsgInternalServerError,
-	CodeSQLRecordDoesNotExist: ErrMsgNotFound,
-	CodeSQLUniqueConstraint:   ErrMsgConflict,
-	CodeSQLConflict:           ErrMsgConflict,
-	CodeSQLNoRowsAffected:     ErrMsgInternalServerError,
+	CodeSQL:                    ErrMsgInternalServerError,
+	CodeSQLInit:                ErrMsgInternalServerError,
+	CodeSQLBuilder:             ErrMsgInternalServerError,
+	CodeSQLTxBegin:             ErrMsgInternalServerError,
+	CodeSQLTxCommit:            ErrMsgInternalServerError,
+	CodeSQLTxRollback:          ErrMsgInternalServerError,
+	CodeSQLTxExec:              ErrMsgInternalServerError,
+	CodeSQLPrepareStmt:         ErrMsgInternalServerError,
+	CodeSQLRead:                ErrMsgInternalServerError,
+	CodeSQLRowScan:             ErrMsgInternalServerError,
+	CodeSQLRecordDoesNotExist:  ErrMsgNotFound,
+	CodeSQLUniqueConstraint:    ErrMsgConflict,
+	CodeSQLConflict:            ErrMsgConflict,
+	CodeSQLNoRowsAffected:      ErrMsgInternalServerError,
+	CodeSQLForeignKeyViolation: ErrMsgBadRequest,
+	CodeSQLNotNullViolation:    ErrMsgBadRequest,
+	CodeSQLDeadlock:            ErrMsgConflict,
+	CodeSQLMigration:           ErrMsgInternalServerError,
+	CodeSQLMigrationLocked:     ErrMsgInternalServerError,
 
 	// Code Cache Error
 	CodeLockExist:            ErrMsgLockExist,
@@ -45,10 +45,22 @@ func Compile(err error, lang string) (int, App) {
 }
 
 func NewWithCode(code codes.Code, msg string, val ...interface{}) error {
-	return create(nil, code, msg, val...)
+	return create(0, nil, code, msg, val...)
 }
 
-func create(cause error, code codes.Code, msg string, val ...interface{}) error {
+// WrapWithCode works like NewWithCode but keeps err as the cause, so Is and As still match it.
+// If code is codes.NoCode, the code of err is used.
+func WrapWithCode(err error, code codes.Code, msg string, val ...interface{}) error {
+	return create(0, err, code, msg, val...)
+}
+
+// WrapWithCodeSkip works like WrapWithCode but records the caller skip frames above its own caller,
+// for helpers that wrap errors on behalf of the function calling them.
+func WrapWithCodeSkip(skip int, err error, code codes.Code, msg string, val ...interface{}) error {
+	return create(skip, err, code, msg, val...)
+}
+
+func create(skip int, cause error, code codes.Code, msg string, val ...interface{}) error {
 	if code == codes.NoCode {
 		code = GetCode(cause)
 	}
@@ -59,7 +71,7 @@ func create(cause error, code codes.Code, msg string, val ...interface{}) error
 		code:    code,
 	}
 
-	pc, file, line, ok := runtime.Caller(2)
+	pc, file, line, ok := runtime.Caller(2 + skip)
 	if !ok {
 		return err
 	}
@@ -109,7 +121,7 @@ func GetCode(err error) codes.Code {
 func GetCaller(err error) (string, int, string, error) {
 	st, ok := err.(*stacktrace) // nolint:errorlint
 	if !ok {
-		return "", 0, "", create(nil, codes.NoCode, "failed to cast to stacktrace")
+		return "", 0, "", create(0, nil, codes.NoCode, "failed to cast to stacktrace")
 	}
 
 	return st.file, st.line, st.message, nil
@@ -166,3 +166,34 @@ func Test_error_GetCallerString(t *testing.T) {
 		})
 	}
 }
+
+func Test_errors_WrapWithCode(t *testing.T) {
+	cause := fmt.Errorf("duplicate key")
+
+	tests := []struct {
+		name     string
+		err      error
+		code     codes.Code
+		wantCode codes.Code
+	}{
+		{
+			name:     "with code",
+			err:      cause,
+			code:     codes.CodeSQLUniqueConstraint,
+			wantCode: codes.CodeSQLUniqueConstraint,
+		},
+		{
+			name:     "code from cause",
+			err:      NewWithCode(codes.CodeSQLConflict, "conflict"),
+			code:     codes.NoCode,
+			wantCode: codes.CodeSQLConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapWithCode(tt.err, tt.code, "insert user")
+			assert.Equal(t, tt.wantCode, GetCode(got))
+			assert.True(t, Is(got, tt.err))
+		})
+	}
+}
@@ -17,6 +17,10 @@ func (st *stacktrace) Error() string {
 	return st.message
 }
 
+func (st *stacktrace) Unwrap() error {
+	return st.cause
+}
+
 func (st *stacktrace) ExitCode() int {
 	if st.code == codes.NoCode {
 		return 1
@@ -0,0 +1,129 @@
+package query
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/reyhanmichiels/go-pkg/v2/operator"
+)
+
+const defaultCursorKey = "id"
+
+type sortColumn struct {
+	column string
+	desc   bool
+}
+
+// EncodeCursor encodes the sort column values of a row, in the order of the sort followed by the cursor key,
+// into an opaque cursor for the `after` and `before` params.
+func EncodeCursor(values ...any) (string, error) {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", errors.NewWithCode(codes.CodeInvalidValue, "failed to encode cursor: %s", err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(cursor string) ([]any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.NewWithCode(codes.CodeInvalidValue, "invalid cursor")
+	}
+
+	// numbers are kept as json.Number, so large ids are not rounded through float64
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var values []any
+	if err := d.Decode(&values); err != nil {
+		return nil, errors.NewWithCode(codes.CodeInvalidValue, "invalid cursor")
+	}
+
+	return values, nil
+}
+
+// processCursor writes the keyset condition of the `after` or `before` cursor and returns the sort to use.
+// The cursor key is appended to the sort so every row has a unique position. With `before` the sort is
+// reversed to read the rows right before the cursor, so they are returned in reverse order.
+func (s *sqlBuilder) processCursor(sorts []sortColumn) ([]sortColumn, error) {
+	if s.afterValue == "" && s.beforeValue == "" {
+		return sorts, nil
+	}
+
+	key := defaultCursorKey
+	if s.option != nil && s.option.CursorKey != "" {
+		key = s.option.CursorKey
+	}
+	key = s.qualify(key)
+
+	hasKey := false
+	for _, sc := range sorts {
+		hasKey = hasKey || sc.column == key
+	}
+	if !hasKey {
+		sorts = append(sorts, sortColumn{column: key})
+	}
+
+	before := s.afterValue == ""
+	values, err := decodeCursor(operator.Ternary(before, s.beforeValue, s.afterValue))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(sorts) {
+		return nil, errors.NewWithCode(codes.CodeInvalidValue, "cursor has %d values, expected %d", len(values), len(sorts))
+	}
+
+	if before {
+		for i := range sorts {
+			sorts[i].desc = !sorts[i].desc
+		}
+	}
+
+	cond, args := keysetCondition(sorts, values)
+	s.rawQuery.WriteString(" AND " + cond)
+	s.fieldValues = append(s.fieldValues, args...)
+
+	return sorts, nil
+}
+
+// keysetCondition selects the rows after values in the given sort. A row comparison is used when every column
+// is sorted in the same direction, which lets the database use a composite index.
+func keysetCondition(sorts []sortColumn, values []any) (string, []any) {
+	sameDirection := true
+	for _, sc := range sorts {
+		sameDirection = sameDirection && sc.desc == sorts[0].desc
+	}
+
+	if sameDirection {
+		columns := make([]string, len(sorts))
+		for i, sc := range sorts {
+			columns[i] = sc.column
+		}
+		op := operator.Ternary(sorts[0].desc, "<", ">")
+		bindVars := strings.TrimSuffix(strings.Repeat("?, ", len(sorts)), ", ")
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), op, bindVars), values
+	}
+
+	// (a > ?) OR (a = ? AND b < ?) OR ...
+	var (
+		ors  []string
+		args []any
+	)
+	for i, sc := range sorts {
+		ands := []string{}
+		for j := 0; j < i; j++ {
+			ands = append(ands, sorts[j].column+"=?")
+			args = append(args, values[j])
+		}
+		ands = append(ands, sc.column+operator.Ternary(sc.desc, "<", ">")+"?")
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
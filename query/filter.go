@@ -0,0 +1,111 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/reyhanmichiels/go-pkg/v2/operator"
+)
+
+// likeEscape is the LIKE escape character, `!` needs no escaping in string literals of any driver unlike `\`.
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// buildFilter writes the condition of the filter operator suffixes, it reports false when the param has none.
+func (s *sqlBuilder) buildFilter(buildOption BuildQueryOption) bool {
+	column, value := buildOption.dbTagValue, buildOption.fieldValue
+
+	switch {
+	case strings.Contains(buildOption.paramTagValue, "__between"):
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice || v.Len() != 2 {
+			s.setErr(errors.NewWithCode(codes.CodeInvalidValue, "%s between value should have 2 elements", column))
+			return true
+		}
+		s.rawQuery.WriteString(" " + column + " BETWEEN " + s.getBindVar() + " AND " + s.getBindVar())
+		s.fieldValues = append(s.fieldValues, v.Index(0).Interface(), v.Index(1).Interface())
+
+	case isNullCheck(buildOption.paramTagValue):
+		isNull, ok := value.(bool)
+		if !ok {
+			s.setErr(errors.NewWithCode(codes.CodeInvalidValue, "%s null check value should be a bool", column))
+			return true
+		}
+		if strings.Contains(buildOption.paramTagValue, "__notnull") {
+			isNull = !isNull
+		}
+		s.rawQuery.WriteString(" " + column + " IS " + operator.Ternary(isNull, "NULL", "NOT NULL"))
+
+	case strings.Contains(buildOption.paramTagValue, "__ilike"):
+		if s.db.Driver() == "postgres" {
+			s.rawQuery.WriteString(" " + column + " ILIKE " + s.getBindVar())
+		} else {
+			s.rawQuery.WriteString(" LOWER(" + column + ") LIKE LOWER(" + s.getBindVar() + ")")
+		}
+		s.fieldValues = append(s.fieldValues, value)
+
+	case strings.Contains(buildOption.paramTagValue, "__startswith"):
+		s.writeLike(column, likeEscaper.Replace(fmt.Sprint(value))+"%")
+
+	case strings.Contains(buildOption.paramTagValue, "__endswith"):
+		s.writeLike(column, "%"+likeEscaper.Replace(fmt.Sprint(value)))
+
+	case strings.Contains(buildOption.paramTagValue, "__jsoncontains"):
+		s.buildJSONContains(column, value)
+
+	case strings.Contains(buildOption.paramTagValue, "__contains"):
+		s.writeLike(column, "%"+likeEscaper.Replace(fmt.Sprint(value))+"%")
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// writeLike writes a LIKE condition of an escaped pattern, so `%` and `_` in user input match literally.
+func (s *sqlBuilder) writeLike(column string, pattern string) {
+	s.rawQuery.WriteString(" " + column + " LIKE " + s.getBindVar() + " ESCAPE '" + likeEscape + "'")
+	s.fieldValues = append(s.fieldValues, pattern)
+}
+
+// buildJSONContains checks that the json column contains value, strings and bytes are taken as encoded json.
+func (s *sqlBuilder) buildJSONContains(column string, value any) {
+	var doc string
+	switch v := value.(type) {
+	case string:
+		doc = v
+	case []byte:
+		doc = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			s.setErr(errors.NewWithCode(codes.CodeInvalidValue, "failed to encode %s json value: %s", column, err.Error()))
+			return
+		}
+		doc = string(b)
+	}
+
+	switch s.db.Driver() {
+	case "postgres":
+		s.rawQuery.WriteString(" " + column + " @> " + s.getBindVar())
+	case "mysql":
+		s.rawQuery.WriteString(" JSON_CONTAINS(" + column + ", " + s.getBindVar() + ")")
+	default:
+		s.setErr(errors.NewWithCode(codes.CodeNotImplemented, "json contains is not supported on %s", s.db.Driver()))
+		return
+	}
+	s.fieldValues = append(s.fieldValues, doc)
+}
+
+// setErr keeps the first error found while building, it is returned once the param is processed.
+func (s *sqlBuilder) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
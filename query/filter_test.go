@@ -0,0 +1,147 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/reyhanmichiels/go-pkg/v2/null"
+	mock_sql "github.com/reyhanmichiels/go-pkg/v2/tests/mock/sql"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type filterParam struct {
+	Age        []int64        `param:"age__between" db:"age"`
+	DeletedAt  null.Bool      `param:"deleted_at__isnull" db:"deleted_at"`
+	VerifiedAt null.Bool      `param:"verified_at__notnull" db:"verified_at"`
+	Name       string         `param:"name__ilike" db:"name"`
+	Email      string         `param:"email__startswith" db:"email"`
+	Phone      string         `param:"phone__endswith" db:"phone"`
+	Bio        string         `param:"bio__contains" db:"bio"`
+	Tags       map[string]any `param:"tags__jsoncontains" db:"tags"`
+}
+
+func Test_sqlBuilder_Build_filter(t *testing.T) {
+	tests := []struct {
+		name      string
+		driver    string
+		param     filterParam
+		wantQuery string
+		wantArgs  []any
+		wantErr   bool
+	}{
+		{
+			name:      "between",
+			driver:    "postgres",
+			param:     filterParam{Age: []int64{18, 30}},
+			wantQuery: " WHERE 1=1 AND age BETWEEN $1 AND $2;",
+			wantArgs:  []any{int64(18), int64(30)},
+		},
+		{
+			name:    "between without 2 values",
+			driver:  "postgres",
+			param:   filterParam{Age: []int64{18}},
+			wantErr: true,
+		},
+		{
+			name:      "null checks",
+			driver:    "postgres",
+			param:     filterParam{DeletedAt: null.BoolFrom(true), VerifiedAt: null.BoolFrom(false)},
+			wantQuery: " WHERE 1=1 AND deleted_at IS NULL AND verified_at IS NULL;",
+		},
+		{
+			name:      "ilike on postgres",
+			driver:    "postgres",
+			param:     filterParam{Name: "%john%"},
+			wantQuery: " WHERE 1=1 AND name ILIKE $1;",
+			wantArgs:  []any{"%john%"},
+		},
+		{
+			name:      "ilike on mysql",
+			driver:    "mysql",
+			param:     filterParam{Name: "%john%"},
+			wantQuery: " WHERE 1=1 AND LOWER(name) LIKE LOWER(?);",
+			wantArgs:  []any{"%john%"},
+		},
+		{
+			name:      "escaped like",
+			driver:    "postgres",
+			param:     filterParam{Email: "john_doe", Phone: "100%", Bio: "a!b"},
+			wantQuery: " WHERE 1=1 AND email LIKE $1 ESCAPE '!' AND phone LIKE $2 ESCAPE '!' AND bio LIKE $3 ESCAPE '!';",
+			wantArgs:  []any{"john!_doe%", "%100!%", "%a!!b%"},
+		},
+		{
+			name:      "json contains on postgres",
+			driver:    "postgres",
+			param:     filterParam{Tags: map[string]any{"role": "admin"}},
+			wantQuery: " WHERE 1=1 AND tags @> $1;",
+			wantArgs:  []any{`{"role":"admin"}`},
+		},
+		{
+			name:      "json contains on mysql",
+			driver:    "mysql",
+			param:     filterParam{Tags: map[string]any{"role": "admin"}},
+			wantQuery: " WHERE 1=1 AND JSON_CONTAINS(tags, ?);",
+			wantArgs:  []any{`{"role":"admin"}`},
+		},
+		{
+			name:    "json contains on sqlite",
+			driver:  "sqlite",
+			param:   filterParam{Tags: map[string]any{"role": "admin"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := mock_sql.NewMockInterface(ctrl)
+			db.EXPECT().Driver().Return(tt.driver).AnyTimes()
+			db.EXPECT().Rebind(gomock.Any()).DoAndReturn(func(query string) string {
+				return sqlx.Rebind(sqlx.BindType(tt.driver), query)
+			}).AnyTimes()
+
+			qb := NewSQLQueryBuilder(db, "param", "db", &Option{DisableLimit: true})
+			query, args, _, _, err := qb.Build(&tt.param)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func Test_sqlBuilder_Build_filterPlainBool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := mock_sql.NewMockInterface(ctrl)
+	db.EXPECT().Driver().Return("postgres").AnyTimes()
+
+	// false must not be dropped as unset, a plain bool is rejected instead
+	param := struct {
+		DeletedAt bool `param:"deleted_at__isnull" db:"deleted_at"`
+	}{DeletedAt: false}
+
+	qb := NewSQLQueryBuilder(db, "param", "db", nil)
+	_, _, _, _, err := qb.Build(&param)
+	assert.Error(t, err)
+}
+
+func Test_sqlBuilder_BuildUpdate_filterPlainBool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := mock_sql.NewMockInterface(ctrl)
+	db.EXPECT().Driver().Return("postgres").AnyTimes()
+
+	update := struct {
+		Name string `param:"name" db:"name"`
+	}{Name: "john"}
+	param := struct {
+		DeletedAt bool `param:"deleted_at__isnull" db:"deleted_at"`
+	}{DeletedAt: true}
+
+	qb := NewSQLQueryBuilder(db, "param", "db", nil)
+	_, _, err := qb.BuildUpdate(&update, &param)
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package query
+
+import (
+	"context"
+)
+
+// Page is a page of items with its pagination metadata, ready to be serialized as a response.
+type Page[T any] struct {
+	Items         []T   `json:"items"`
+	TotalElements int64 `json:"total_elements"`
+	TotalPages    int64 `json:"total_pages"`
+	CurrentPage   int64 `json:"current_page"`
+	Limit         int64 `json:"limit"`
+}
+
+// Paginate builds the conditions of param with qb, appends them to query and countQuery,
+// then selects the items and counts every matching row through the builder database.
+//
+//	page, err := query.Paginate[User](ctx, qb, "list users", "SELECT * FROM users", "SELECT COUNT(*) FROM users", &param)
+//
+// Items of a `before` cursor page are put back in the requested order.
+func Paginate[T any](ctx context.Context, qb *sqlBuilder, name string, query string, countQuery string, param any) (Page[T], error) {
+	b, err := qb.build(param)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	page := Page[T]{
+		Items:       []T{},
+		CurrentPage: max(b.page, 1),
+		Limit:       b.limit,
+	}
+
+	if err := qb.db.Select(ctx, name, query+b.query, &page.Items, b.args...); err != nil {
+		return Page[T]{}, err
+	}
+
+	if err := qb.db.Get(ctx, name+" count", countQuery+b.countQuery, &page.TotalElements, b.countArgs...); err != nil {
+		return Page[T]{}, err
+	}
+
+	if b.before {
+		for i, j := 0, len(page.Items)-1; i < j; i, j = i+1, j-1 {
+			page.Items[i], page.Items[j] = page.Items[j], page.Items[i]
+		}
+	}
+
+	page.TotalPages = totalPages(page.TotalElements, page.Limit)
+
+	return page, nil
+}
+
+func totalPages(total, limit int64) int64 {
+	if limit <= 0 {
+		return min(total, 1)
+	}
+	return (total + limit - 1) / limit
+}
@@ -0,0 +1,76 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	mock_sql "github.com/reyhanmichiels/go-pkg/v2/tests/mock/sql"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type pageUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func Test_query_Paginate(t *testing.T) {
+	tests := []struct {
+		name      string
+		param     userParam
+		items     []pageUser
+		total     int64
+		wantQuery string
+		want      Page[pageUser]
+	}{
+		{
+			name:      "page",
+			param:     userParam{Name: "john", Page: 2, Limit: 2},
+			items:     []pageUser{{ID: 3, Name: "john"}, {ID: 4, Name: "john"}},
+			total:     5,
+			wantQuery: "SELECT * FROM users WHERE 1=1 AND name=$1 LIMIT 2 OFFSET 2;",
+			want:      Page[pageUser]{Items: []pageUser{{ID: 3, Name: "john"}, {ID: 4, Name: "john"}}, TotalElements: 5, TotalPages: 3, CurrentPage: 2, Limit: 2},
+		},
+		{
+			name:      "before cursor is put back in order",
+			param:     userParam{Before: mockCursor(t, 5), Limit: 2},
+			items:     []pageUser{{ID: 4}, {ID: 3}},
+			total:     5,
+			wantQuery: "SELECT * FROM users WHERE 1=1 AND (id) < ($1) ORDER BY id DESC LIMIT 2;",
+			want:      Page[pageUser]{Items: []pageUser{{ID: 3}, {ID: 4}}, TotalElements: 5, TotalPages: 3, CurrentPage: 1, Limit: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := mock_sql.NewMockInterface(ctrl)
+			db.EXPECT().Driver().Return("postgres").AnyTimes()
+			db.EXPECT().Rebind(gomock.Any()).DoAndReturn(func(query string) string {
+				return sqlx.Rebind(sqlx.DOLLAR, query)
+			}).AnyTimes()
+			db.EXPECT().Select(gomock.Any(), "list users", tt.wantQuery, gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _, _ string, dest any, _ ...any) error {
+					*dest.(*[]pageUser) = tt.items
+					return nil
+				})
+			db.EXPECT().Get(gomock.Any(), "list users count", gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _, _ string, dest any, _ ...any) error {
+					*dest.(*int64) = tt.total
+					return nil
+				})
+
+			qb := NewSQLQueryBuilder(db, "param", "db", nil)
+			got, err := Paginate[pageUser](context.Background(), qb, "list users", "SELECT * FROM users", "SELECT COUNT(*) FROM users", &tt.param)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_query_totalPages(t *testing.T) {
+	assert.Equal(t, int64(3), totalPages(5, 2))
+	assert.Equal(t, int64(2), totalPages(4, 2))
+	assert.Equal(t, int64(0), totalPages(0, 2))
+	assert.Equal(t, int64(1), totalPages(5, 0))
+}
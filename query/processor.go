@@ -1,13 +1,17 @@
 package query
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
 	"github.com/reyhanmichiels/go-pkg/v2/null"
+	"github.com/reyhanmichiels/go-pkg/v2/operator"
 )
 
 /*
@@ -33,6 +37,11 @@ func (s *sqlBuilder) processParam(param reflect.Value, paramTagValue string, dbT
 					continue
 				}
 
+				if group := param.Type().Field(i).Tag.Get("group"); group != "" && !isUpdate && param.Field(i).Kind() == reflect.Struct {
+					s.processGroup(param.Field(i), group)
+					continue
+				}
+
 				s.processParam(param.Field(i), paramTagValue, dbTagValue, isUpdate)
 			}
 		}
@@ -42,6 +51,27 @@ func (s *sqlBuilder) processParam(param reflect.Value, paramTagValue string, dbT
 	}
 }
 
+// processGroup renders the conditions of a nested struct tagged `group:"or"` or `group:"and"` as one
+// parenthesised block, e.g. ` AND (name LIKE ? OR email LIKE ?)`. Groups can be nested.
+func (s *sqlBuilder) processGroup(param reflect.Value, group string) {
+	parentQuery, parentOperator := s.rawQuery, s.groupOperator
+
+	s.rawQuery = &bytes.Buffer{}
+	s.groupOperator = operator.Ternary(strings.EqualFold(group, "or"), " OR", " AND")
+	s.processParam(param, "", "", false)
+	conditions := s.rawQuery.String()
+
+	// the first condition is written with the group operator, which has nothing to join inside the group
+	conditions = strings.TrimPrefix(conditions, s.groupOperator)
+	s.rawQuery, s.groupOperator = parentQuery, parentOperator
+
+	if conditions == "" {
+		return
+	}
+
+	s.rawQuery.WriteString(operator.Ternary(parentOperator == "", " AND", parentOperator) + " (" + strings.TrimSpace(conditions) + ")")
+}
+
 // collect element build option and build the query
 func (s *sqlBuilder) processElem(element reflect.Value, paramTagValue string, dbTagValue string, isUpdate bool) {
 	buildOption := BuildQueryOption{
@@ -69,6 +99,22 @@ func (s *sqlBuilder) processElem(element reflect.Value, paramTagValue string, db
 		return
 	}
 
+	if isAfter(paramTagValue) {
+		s.afterValue = element.String()
+		return
+	}
+
+	if isBefore(paramTagValue) {
+		s.beforeValue = element.String()
+		return
+	}
+
+	// a plain bool would drop false as unset, so the negated check could never be built
+	if isNullCheck(paramTagValue) && element.Kind() == reflect.Bool {
+		s.setErr(errors.NewWithCode(codes.CodeInvalidValue, "%s null check should be a null.Bool, a bool cannot tell false from unset", dbTagValue))
+		return
+	}
+
 	buildOption = s.setBuildOption(element, buildOption)
 
 	if isUpdate {
@@ -259,33 +305,63 @@ func (s *sqlBuilder) setBuildOption(element reflect.Value, buildOption BuildQuer
 	return buildOption
 }
 
-func (s *sqlBuilder) processSort() {
-	sortValue := []string{}
+// sortColumns returns the requested sort, keeping only columns that exist in the param.
+func (s *sqlBuilder) sortColumns() []sortColumn {
+	sorts := []sortColumn{}
 	for _, v := range s.sortValue {
-		sortOrder := "ASC"
+		desc := false
 		if !regexp.MustCompile(`(?P<sign>-)?(?P<col>[a-zA-Z_\\.0-9]+),?`).MatchString(v) {
 			continue
 		}
 
 		if strings.Contains(v, "-") {
-			sortOrder = "DESC"
+			desc = true
 			v = strings.Split(v, "-")[1]
 		}
 
 		if s.mapDBTagExist[v] {
-			sortValue = append(sortValue, fmt.Sprintf("%v %v", v, sortOrder))
+			sorts = append(sorts, sortColumn{column: v, desc: desc})
 		}
 	}
 
+	return sorts
+}
+
+func (s *sqlBuilder) qualifySorts(sorts []sortColumn) []sortColumn {
+	for i := range sorts {
+		sorts[i].column = s.qualify(sorts[i].column)
+	}
+	return sorts
+}
+
+func (s *sqlBuilder) processSort(sorts []sortColumn) {
+	sortValue := []string{}
+	for _, v := range sorts {
+		sortValue = append(sortValue, fmt.Sprintf("%v %v", v.column, operator.Ternary(v.desc, "DESC", "ASC")))
+	}
+
 	if len(sortValue) > 0 {
 		s.rawQuery.WriteString(" ORDER BY " + strings.Join(sortValue, ", "))
 	}
 }
 
+// processPagination writes the limit clause of the driver, mysql uses `LIMIT offset, limit` while postgres and
+// sqlite use `LIMIT limit OFFSET offset`. Cursor pagination only needs the limit, the cursor replaces the offset.
 func (s *sqlBuilder) processPagination() {
+	if s.afterValue != "" || s.beforeValue != "" {
+		if s.limitValue > 0 {
+			s.rawQuery.WriteString(fmt.Sprintf(" LIMIT %d", s.limitValue))
+		}
+		return
+	}
+
 	if s.pageValue > 0 || s.limitValue > 0 {
 		offset := getOffset(s.pageValue, s.limitValue)
-		s.rawQuery.WriteString(fmt.Sprintf(" LIMIT %d, %d", offset, s.limitValue))
+		if s.db.Driver() == "mysql" {
+			s.rawQuery.WriteString(fmt.Sprintf(" LIMIT %d, %d", offset, s.limitValue))
+			return
+		}
+		s.rawQuery.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", s.limitValue, offset))
 	}
 }
 
@@ -318,6 +394,18 @@ func isLimit(paramTagValue string) bool {
 	return paramTagValue == "limit"
 }
 
+func isAfter(paramTagValue string) bool {
+	return paramTagValue == "after"
+}
+
+func isBefore(paramTagValue string) bool {
+	return paramTagValue == "before"
+}
+
+func isNullCheck(paramTagValue string) bool {
+	return strings.Contains(paramTagValue, "__isnull") || strings.Contains(paramTagValue, "__notnull")
+}
+
 func isSortBy(paramTagValue string) bool {
 	return paramTagValue == "sort-by" || paramTagValue == "sort_by" || paramTagValue == "sortBy" || paramTagValue == "sortby"
 }
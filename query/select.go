@@ -0,0 +1,130 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/reyhanmichiels/go-pkg/v2/operator"
+)
+
+// SelectOption is the table BuildSelect reads from.
+type SelectOption struct {
+	Table string
+	// Alias of the table, unqualified result columns and conditions are prefixed with it,
+	// or with Table when there are joins and no alias.
+	Alias string
+	// Joins are appended to the FROM clause as is, e.g. "JOIN roles r ON r.id = u.role_id".
+	Joins []string
+	// PrimaryKey is counted once per row of the table when there are joins, defaults to id.
+	PrimaryKey string
+}
+
+// BuildSelect builds a complete `SELECT` and `SELECT COUNT(*)` of param, with the columns taken from the db tags of
+// result, a struct or a pointer or slice of it. A field can read a joined column with the `column` tag:
+//
+//	RoleName string `db:"role_name" column:"r.name"`
+//
+// selects `r.name AS role_name`.
+func (s *sqlBuilder) BuildSelect(result interface{}, param interface{}, opt SelectOption) (string, []interface{}, string, []interface{}, error) {
+	if opt.Table == "" {
+		return "", nil, "", nil, errors.NewWithCode(codes.CodeInvalidValue, "select table cannot be empty")
+	}
+
+	alias := opt.Alias
+	if alias == "" && len(opt.Joins) > 0 {
+		alias = opt.Table
+	}
+
+	columns, err := s.selectColumns(result, alias)
+	if err != nil {
+		return "", nil, "", nil, err
+	}
+
+	s.alias = alias
+	b, err := s.build(param)
+	if err != nil {
+		return "", nil, "", nil, err
+	}
+
+	// a one to many join repeats the rows of the table, so they are counted by their primary key
+	count := "COUNT(*)"
+	if len(opt.Joins) > 0 {
+		count = fmt.Sprintf("COUNT(DISTINCT %s.%s)", alias, operator.Ternary(opt.PrimaryKey == "", defaultCursorKey, opt.PrimaryKey))
+	}
+
+	from := fromClause(opt)
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM " + from + b.query
+	countQuery := "SELECT " + count + " FROM " + from + b.countQuery
+
+	return query, b.args, countQuery, b.countArgs, nil
+}
+
+func (s *sqlBuilder) selectColumns(result interface{}, alias string) ([]string, error) {
+	t := reflect.TypeOf(result)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.NewWithCode(codes.CodeInvalidValue, "select result should be a struct")
+	}
+
+	columns := []string{}
+	s.walkSelectColumns(t, func(field reflect.StructField, dbTagValue string) {
+		column := field.Tag.Get("column")
+		switch {
+		case column != "":
+			columns = append(columns, fmt.Sprintf("%s AS %s", column, dbTagValue))
+		case alias != "":
+			columns = append(columns, alias+"."+dbTagValue)
+		default:
+			columns = append(columns, dbTagValue)
+		}
+	})
+
+	if len(columns) == 0 {
+		return nil, errors.NewWithCode(codes.CodeInvalidValue, "select result %s has no %s tag", t.Name(), s.dbTag)
+	}
+
+	return columns, nil
+}
+
+// walkSelectColumns calls fn for every field with a db tag, embedded structs without a tag are walked as well.
+func (s *sqlBuilder) walkSelectColumns(t reflect.Type, fn func(field reflect.StructField, dbTagValue string)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		dbTagValue, hasTag := field.Tag.Lookup(s.dbTag)
+		dbTagValue = strings.Split(dbTagValue, ",")[0]
+		if dbTagValue == "-" {
+			continue
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && !hasTag && ft.Kind() == reflect.Struct {
+			s.walkSelectColumns(ft, fn)
+			continue
+		}
+
+		if !field.IsExported() || dbTagValue == "" {
+			continue
+		}
+		fn(field, dbTagValue)
+	}
+}
+
+func fromClause(opt SelectOption) string {
+	from := opt.Table
+	if opt.Alias != "" {
+		from += " " + opt.Alias
+	}
+	for _, join := range opt.Joins {
+		from += " " + join
+	}
+	return from
+}
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	mock_sql "github.com/reyhanmichiels/go-pkg/v2/tests/mock/sql"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type selectAudit struct {
+	CreatedAt string `db:"created_at"`
+}
+
+type selectUser struct {
+	selectAudit
+	ID       int64  `db:"id"`
+	Name     string `db:"name"`
+	RoleName string `db:"role_name" column:"r.name"`
+	Password string `db:"-"`
+}
+
+type selectParam struct {
+	Name  string `param:"name" db:"u.name"`
+	Page  int64  `param:"page" db:"page"`
+	Limit int64  `param:"limit" db:"limit"`
+}
+
+func Test_sqlBuilder_BuildSelect(t *testing.T) {
+	tests := []struct {
+		name           string
+		result         any
+		opt            SelectOption
+		wantQuery      string
+		wantCountQuery string
+		wantErr        bool
+	}{
+		{
+			name:           "table",
+			result:         &[]selectUser{},
+			opt:            SelectOption{Table: "users"},
+			wantQuery:      "SELECT created_at, id, name, r.name AS role_name FROM users WHERE 1=1 AND u.name=$1 LIMIT 10 OFFSET 0;",
+			wantCountQuery: "SELECT COUNT(*) FROM users WHERE 1=1 AND u.name=$1;",
+		},
+		{
+			name:           "alias and joins",
+			result:         selectUser{},
+			opt:            SelectOption{Table: "users", Alias: "u", Joins: []string{"JOIN roles r ON r.id = u.role_id"}},
+			wantQuery:      "SELECT u.created_at, u.id, u.name, r.name AS role_name FROM users u JOIN roles r ON r.id = u.role_id WHERE 1=1 AND u.name=$1 LIMIT 10 OFFSET 0;",
+			wantCountQuery: "SELECT COUNT(DISTINCT u.id) FROM users u JOIN roles r ON r.id = u.role_id WHERE 1=1 AND u.name=$1;",
+		},
+		{
+			name:    "empty table",
+			result:  selectUser{},
+			wantErr: true,
+		},
+		{
+			name:    "result is not a struct",
+			result:  []string{},
+			opt:     SelectOption{Table: "users"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := mock_sql.NewMockInterface(ctrl)
+			db.EXPECT().Driver().Return("postgres").AnyTimes()
+			db.EXPECT().Rebind(gomock.Any()).DoAndReturn(func(query string) string {
+				return sqlx.Rebind(sqlx.DOLLAR, query)
+			}).AnyTimes()
+
+			qb := NewSQLQueryBuilder(db, "param", "db", nil)
+			query, args, countQuery, countArgs, err := qb.BuildSelect(tt.result, &selectParam{Name: "john", Limit: 10}, tt.opt)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, []any{"john"}, args)
+			assert.Equal(t, tt.wantCountQuery, countQuery)
+			assert.Equal(t, []any{"john"}, countArgs)
+		})
+	}
+}
+
+func Test_sqlBuilder_BuildSelect_join(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := mock_sql.NewMockInterface(ctrl)
+	db.EXPECT().Driver().Return("postgres").AnyTimes()
+	db.EXPECT().Rebind(gomock.Any()).DoAndReturn(func(query string) string {
+		return sqlx.Rebind(sqlx.DOLLAR, query)
+	}).AnyTimes()
+
+	// roles has a name and a status too, so every condition must be qualified
+	param := struct {
+		Name     string   `param:"name" db:"name"`
+		RoleName string   `param:"role_name" db:"r.name"`
+		SortBy   []string `param:"sort_by" db:"sort_by"`
+	}{Name: "john", RoleName: "admin", SortBy: []string{"-name"}}
+
+	qb := NewSQLQueryBuilder(db, "param", "db", &Option{IsActive: true, DisableLimit: true})
+	query, args, countQuery, countArgs, err := qb.BuildSelect(&[]selectUser{}, &param, SelectOption{
+		Table:      "users",
+		Joins:      []string{"JOIN roles r ON r.id = users.role_id"},
+		PrimaryKey: "user_id",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT users.created_at, users.id, users.name, r.name AS role_name FROM users JOIN roles r ON r.id = users.role_id"+
+		" WHERE 1=1 AND users.status=1 AND users.name=$1 AND r.name=$2 ORDER BY users.name DESC;", query)
+	assert.Equal(t, []any{"john", "admin"}, args)
+	assert.Equal(t, "SELECT COUNT(DISTINCT users.user_id) FROM users JOIN roles r ON r.id = users.role_id"+
+		" WHERE 1=1 AND users.status=1 AND users.name=$1 AND r.name=$2;", countQuery)
+	assert.Equal(t, []any{"john", "admin"}, countArgs)
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"reflect"
 	"strings"
-	"sync"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/reyhanmichiels/go-pkg/v2/codes"
@@ -17,6 +16,8 @@ type Option struct {
 	DisableLimit bool `form:"disableLimit"`
 	IsActive     bool
 	IsInactive   bool
+	// CursorKey is the unique column appended to the sort of cursor pagination, defaults to id.
+	CursorKey string
 }
 
 type BuildQueryOption struct {
@@ -41,6 +42,11 @@ type sqlBuilder struct {
 	sortValue     []string
 	pageValue     int64
 	limitValue    int64
+	afterValue    string
+	beforeValue   string
+	groupOperator string // groupOperator joins the conditions of the group being processed.
+	alias         string // alias qualifies the columns of the conditions, it is set by BuildSelect.
+	err           error
 	mapDBTagExist map[string]bool
 	option        *Option
 }
@@ -56,61 +62,81 @@ func NewSQLQueryBuilder(db sql.Interface, paramTag, dbTag string, option *Option
 		option:        option,
 	}
 
-	if option != nil {
-		if option.DisableLimit {
-			qb.disableLimit = true
-		}
-		if option.IsActive {
-			_, _ = qb.rawQuery.WriteString(" AND status=1")
-		}
-		if option.IsInactive {
-			_, _ = qb.rawQuery.WriteString(" AND status=-1")
-		}
+	if option != nil && option.DisableLimit {
+		qb.disableLimit = true
 	}
 
 	return &qb
 }
 
+type builtQuery struct {
+	query      string
+	args       []interface{}
+	countQuery string
+	countArgs  []interface{}
+	page       int64
+	limit      int64
+	before     bool
+}
+
 func (s *sqlBuilder) Build(param interface{}) (string, []interface{}, string, []interface{}, error) {
-	var (
-		newQuery      string
-		newArgs       []interface{}
-		newCountQuery string
-		newCountArgs  []interface{}
-	)
+	b, err := s.build(param)
+	if err != nil {
+		return "", nil, "", nil, err
+	}
+
+	return b.query, b.args, b.countQuery, b.countArgs, nil
+}
+
+func (s *sqlBuilder) build(param interface{}) (builtQuery, error) {
+	defer s.restoreStruct()
 
 	paramReflectVal := reflect.ValueOf(param)
 	if paramReflectVal.Kind() != reflect.Ptr || paramReflectVal.IsNil() {
-		return newQuery, newArgs, newCountQuery, newCountArgs, errors.NewWithCode(codes.CodeInvalidValue, "passed param should be a pointer and cannot be nil")
+		return builtQuery{}, errors.NewWithCode(codes.CodeInvalidValue, "passed param should be a pointer and cannot be nil")
 	}
 
 	s.param = paramReflectVal
 
+	s.processOption()
 	s.processParam(paramReflectVal, "", "", false)
+	if s.err != nil {
+		return builtQuery{}, s.err
+	}
 
-	countQuery := s.rawQuery.Bytes()
+	countQuery := s.rawQuery.String()
+	countValues := s.fieldValues
+
+	sorts, err := s.processCursor(s.qualifySorts(s.sortColumns()))
+	if err != nil {
+		return builtQuery{}, err
+	}
 
-	s.processSort()
+	s.processSort(sorts)
 
 	if !s.disableLimit {
 		s.processPagination()
 	}
 
-	newQuery, newArgs, err := sqlx.In(s.rawQuery.String()+";", s.fieldValues...)
-	if err != nil {
-		return "", nil, "", nil, err
+	b := builtQuery{
+		page:   s.pageValue,
+		limit:  operator.Ternary(s.disableLimit, 0, s.limitValue),
+		before: s.afterValue == "" && s.beforeValue != "",
 	}
-	newQuery = s.db.Rebind(newQuery)
 
-	newCountQuery, newCountArgs, err = sqlx.In(string(countQuery)+";", s.fieldValues...)
+	b.query, b.args, err = sqlx.In(s.rawQuery.String()+";", s.fieldValues...)
 	if err != nil {
-		return "", nil, "", nil, err
+		return builtQuery{}, err
 	}
-	newCountQuery = s.db.Rebind(newCountQuery)
+	b.query = s.db.Rebind(b.query)
 
-	s.restoreStruct()
+	b.countQuery, b.countArgs, err = sqlx.In(countQuery+";", countValues...)
+	if err != nil {
+		return builtQuery{}, err
+	}
+	b.countQuery = s.db.Rebind(b.countQuery)
 
-	return newQuery, newArgs, newCountQuery, newCountArgs, nil
+	return b, nil
 }
 
 func (s *sqlBuilder) BuildUpdate(updateParam interface{}, queryParam interface{}) (string, []interface{}, error) {
@@ -129,20 +155,15 @@ func (s *sqlBuilder) BuildUpdate(updateParam interface{}, queryParam interface{}
 		return newQuery, newArgs, errors.NewWithCode(codes.CodeInvalidValue, "passed query param should be a pointer and cannot be nil")
 	}
 
-	group := sync.WaitGroup{}
-	group.Add(2)
-
-	go func() {
-		defer group.Done()
-		s.processParam(updateParamReflectVal, "", "", true)
-	}()
+	s.processOption()
 
-	go func() {
-		defer group.Done()
-		s.processParam(queryParamReflectVal, "", "", false)
-	}()
+	// both params share the builder state, so they are processed one after the other
+	s.processParam(updateParamReflectVal, "", "", true)
+	s.processParam(queryParamReflectVal, "", "", false)
 
-	group.Wait()
+	if s.err != nil {
+		return "", nil, s.err
+	}
 
 	if strings.TrimSpace(s.rawQuery.String()) == "WHERE 1=1" || strings.TrimSpace(s.rawUpdate.String()) == "SET" {
 		return "", nil, errors.NewWithCode(codes.CodeInvalidValue, "generated query or update clause cannot be empty")
@@ -163,6 +184,7 @@ func (s *sqlBuilder) BuildUpdate(updateParam interface{}, queryParam interface{}
 
 func (s *sqlBuilder) buildQuery(buildOption BuildQueryOption) {
 	s.mapDBTagExist[buildOption.dbTagValue] = true
+	buildOption.dbTagValue = s.qualify(buildOption.dbTagValue)
 
 	if buildOption.fieldValue == nil {
 		return
@@ -177,7 +199,11 @@ func (s *sqlBuilder) buildQuery(buildOption BuildQueryOption) {
 	if strings.Contains(buildOption.paramTagValue, "__opt") {
 		s.rawQuery.WriteString(" OR")
 	} else {
-		s.rawQuery.WriteString(" AND")
+		s.rawQuery.WriteString(operator.Ternary(s.groupOperator == "", " AND", s.groupOperator))
+	}
+
+	if s.buildFilter(buildOption) {
+		return
 	}
 
 	// write condition clause if value is not slices
@@ -236,22 +262,38 @@ func (s *sqlBuilder) getBindVar() string {
 
 func (s *sqlBuilder) restoreStruct() {
 	*s = sqlBuilder{
+		db:            s.db,
 		rawQuery:      bytes.NewBufferString(" WHERE 1=1"),
+		rawUpdate:     bytes.NewBufferString(" SET"),
 		dbTag:         s.dbTag,
 		paramTag:      s.paramTag,
 		mapDBTagExist: map[string]bool{},
 		disableLimit:  s.disableLimit,
+		option:        s.option,
 	}
 
-	if s.option != nil {
-		if s.option.DisableLimit {
-			s.disableLimit = true
-		}
-		if s.option.IsActive {
-			s.rawQuery.WriteString(" AND status=1")
-		}
-		if s.option.IsInactive {
-			s.rawQuery.WriteString(" AND status=-1")
-		}
+	if s.option != nil && s.option.DisableLimit {
+		s.disableLimit = true
+	}
+}
+
+// processOption writes the status conditions of the option, once the table alias is known.
+func (s *sqlBuilder) processOption() {
+	if s.option == nil {
+		return
+	}
+	if s.option.IsActive {
+		s.rawQuery.WriteString(" AND " + s.qualify("status") + "=1")
+	}
+	if s.option.IsInactive {
+		s.rawQuery.WriteString(" AND " + s.qualify("status") + "=-1")
+	}
+}
+
+// qualify prefixes column with the table alias of BuildSelect, unless it is qualified already.
+func (s *sqlBuilder) qualify(column string) string {
+	if s.alias == "" || strings.ContainsAny(column, ".(") {
+		return column
 	}
+	return s.alias + "." + column
 }
@@ -0,0 +1,192 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	mock_sql "github.com/reyhanmichiels/go-pkg/v2/tests/mock/sql"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type userParam struct {
+	Name   string   `param:"name" db:"name"`
+	SortBy []string `param:"sort_by" db:"sort_by"`
+	After  string   `param:"after" db:"after"`
+	Before string   `param:"before" db:"before"`
+	Page   int64    `param:"page" db:"page"`
+	Limit  int64    `param:"limit" db:"limit"`
+	// created_at is only listed so it can be sorted on
+	CreatedAt string `param:"created_at" db:"created_at"`
+}
+
+func mockCursor(t *testing.T, values ...any) string {
+	cursor, err := EncodeCursor(values...)
+	assert.NoError(t, err)
+	return cursor
+}
+
+func Test_sqlBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name           string
+		driver         string
+		param          userParam
+		wantQuery      string
+		wantArgs       []any
+		wantCountQuery string
+		wantCountArgs  []any
+		wantErr        bool
+	}{
+		{
+			name:           "mysql page",
+			driver:         "mysql",
+			param:          userParam{Name: "john", Page: 2, Limit: 10},
+			wantQuery:      " WHERE 1=1 AND name=? LIMIT 10, 10;",
+			wantArgs:       []any{"john"},
+			wantCountQuery: " WHERE 1=1 AND name=?;",
+			wantCountArgs:  []any{"john"},
+		},
+		{
+			name:           "postgres page",
+			driver:         "postgres",
+			param:          userParam{Name: "john", Page: 2, Limit: 10},
+			wantQuery:      " WHERE 1=1 AND name=$1 LIMIT 10 OFFSET 10;",
+			wantArgs:       []any{"john"},
+			wantCountQuery: " WHERE 1=1 AND name=$1;",
+			wantCountArgs:  []any{"john"},
+		},
+		{
+			name:           "postgres after cursor",
+			driver:         "postgres",
+			param:          userParam{Name: "john", SortBy: []string{"created_at"}, After: mockCursor(t, "2024-01-01", 7), Limit: 10},
+			wantQuery:      " WHERE 1=1 AND name=$1 AND (created_at, id) > ($2, $3) ORDER BY created_at ASC, id ASC LIMIT 10;",
+			wantArgs:       []any{"john", "2024-01-01", json.Number("7")},
+			wantCountQuery: " WHERE 1=1 AND name=$1;",
+			wantCountArgs:  []any{"john"},
+		},
+		{
+			name:           "postgres before cursor",
+			driver:         "postgres",
+			param:          userParam{SortBy: []string{"created_at"}, Before: mockCursor(t, "2024-01-01", 7), Limit: 10},
+			wantQuery:      " WHERE 1=1 AND (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT 10;",
+			wantArgs:       []any{"2024-01-01", json.Number("7")},
+			wantCountQuery: " WHERE 1=1;",
+		},
+		{
+			name:           "postgres after cursor with mixed sort",
+			driver:         "postgres",
+			param:          userParam{SortBy: []string{"-created_at"}, After: mockCursor(t, "2024-01-01", 7), Limit: 10},
+			wantQuery:      " WHERE 1=1 AND ((created_at<$1) OR (created_at=$2 AND id>$3)) ORDER BY created_at DESC, id ASC LIMIT 10;",
+			wantArgs:       []any{"2024-01-01", "2024-01-01", json.Number("7")},
+			wantCountQuery: " WHERE 1=1;",
+		},
+		{
+			name:    "cursor with wrong number of values",
+			driver:  "postgres",
+			param:   userParam{SortBy: []string{"created_at"}, After: mockCursor(t, 7)},
+			wantErr: true,
+		},
+		{
+			name:    "malformed cursor",
+			driver:  "postgres",
+			param:   userParam{After: "not a cursor"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := mock_sql.NewMockInterface(ctrl)
+			db.EXPECT().Driver().Return(tt.driver).AnyTimes()
+			db.EXPECT().Rebind(gomock.Any()).DoAndReturn(func(query string) string {
+				return sqlx.Rebind(sqlx.BindType(tt.driver), query)
+			}).AnyTimes()
+
+			qb := NewSQLQueryBuilder(db, "param", "db", nil)
+			query, args, countQuery, countArgs, err := qb.Build(&tt.param)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantArgs, args)
+			assert.Equal(t, tt.wantCountQuery, countQuery)
+			assert.Equal(t, tt.wantCountArgs, countArgs)
+		})
+	}
+}
+
+type searchParam struct {
+	Name  string `param:"name" db:"name"`
+	Email string `param:"email" db:"email"`
+}
+
+type groupParam struct {
+	Status []int64     `param:"status" db:"status"`
+	Search searchParam `group:"or"`
+	Role   struct {
+		Role  string `param:"role" db:"role"`
+		Admin struct {
+			Origin  string `param:"origin" db:"ORIGIN"`
+			IsAdmin bool   `param:"is_admin" db:"is_admin"`
+			Level   string `param:"level__gte" db:"level"`
+		} `group:"and"`
+	} `group:"or"`
+}
+
+func Test_sqlBuilder_Build_group(t *testing.T) {
+	tests := []struct {
+		name      string
+		param     groupParam
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name: "or group",
+			param: groupParam{
+				Status: []int64{1, 2},
+				Search: searchParam{Name: "%john%", Email: "%john%"},
+			},
+			wantQuery: " WHERE 1=1 AND status IN ($1, $2) AND (name LIKE $3 OR email LIKE $4);",
+			wantArgs:  []any{int64(1), int64(2), "%john%", "%john%"},
+		},
+		{
+			name: "nested group",
+			param: func() groupParam {
+				p := groupParam{}
+				p.Role.Role = "owner"
+				p.Role.Admin.Origin = "web"
+				p.Role.Admin.IsAdmin = true
+				p.Role.Admin.Level = "3"
+				return p
+			}(),
+			wantQuery: " WHERE 1=1 AND (role=$1 OR (ORIGIN=$2 AND is_admin=$3 AND level>=$4));",
+			wantArgs:  []any{"owner", "web", true, "3"},
+		},
+		{
+			name:      "empty group",
+			param:     groupParam{Status: []int64{1}},
+			wantQuery: " WHERE 1=1 AND status IN ($1);",
+			wantArgs:  []any{int64(1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := mock_sql.NewMockInterface(ctrl)
+			db.EXPECT().Driver().Return("postgres").AnyTimes()
+			db.EXPECT().Rebind(gomock.Any()).DoAndReturn(func(query string) string {
+				return sqlx.Rebind(sqlx.DOLLAR, query)
+			}).AnyTimes()
+
+			qb := NewSQLQueryBuilder(db, "param", "db", &Option{DisableLimit: true})
+			query, args, _, _, err := qb.Build(&tt.param)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/reyhanmichiels/go-pkg/v2/log"
+	"github.com/reyhanmichiels/go-pkg/v2/sql"
+)
+
+const (
+	defaultTable   = "schema_migrations"
+	defaultLockKey = "schema_migrations"
+
+	migrateUpLogMessage   = "SQL: migrated up %d_%s, took %s"
+	migrateDownLogMessage = "SQL: migrated down %d_%s, took %s"
+)
+
+// Interface runs versioned migrations against the leader of a sql.Interface.
+// Every migration runs in its own transaction together with the update of the migration table,
+// and every operation but Status takes a lock so only one instance migrates at a time.
+type Interface interface {
+	// Up applies every pending migration.
+	Up(ctx context.Context) error
+	// Down rolls back the last n applied migrations.
+	Down(ctx context.Context, n int) error
+	// Goto applies or rolls back migrations until version is the latest applied one, zero rolls back everything.
+	Goto(ctx context.Context, version uint64) error
+	// Status lists the migrations sorted by version, followed by the applied ones whose files are missing.
+	// It does not take the lock, so it does not wait for a running migration.
+	Status(ctx context.Context) ([]Status, error)
+}
+
+// Config configures the migration source and table. Migration files are named
+// `<version>_<name>.up.sql` and `<version>_<name>.down.sql`, the down file is optional.
+//
+// The lock holds one connection on postgres and mysql while the migrations run on another one,
+// so the pool needs at least two open connections or migrating deadlocks. On mysql the connection
+// must also allow multiple statements per file.
+type Config struct {
+	Dir     string
+	FS      fs.FS  // FS takes precedence over Dir, e.g. an embed.FS narrowed with fs.Sub.
+	Table   string // Table defaults to schema_migrations.
+	LockKey string // LockKey identifies the advisory lock, defaults to schema_migrations.
+}
+
+// Status is the state of one migration. Missing is true when it is applied but its files are gone.
+type Status struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Missing   bool
+}
+
+type migrator struct {
+	cfg Config
+	db  sql.Interface
+	log log.Interface
+}
+
+func Init(cfg Config, db sql.Interface, log log.Interface) Interface {
+	if cfg.Table == "" {
+		cfg.Table = defaultTable
+	}
+	if cfg.LockKey == "" {
+		cfg.LockKey = defaultLockKey
+	}
+
+	return &migrator{
+		cfg: cfg,
+		db:  db,
+		log: log,
+	}
+}
+
+func (m *migrator) Up(ctx context.Context) error {
+	return m.run(ctx, func(migrations []migration, applied map[uint64]appliedMigration) error {
+		for _, mg := range migrations {
+			if _, ok := applied[mg.version]; ok {
+				continue
+			}
+			if err := m.up(ctx, mg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *migrator) Down(ctx context.Context, n int) error {
+	return m.run(ctx, func(migrations []migration, applied map[uint64]appliedMigration) error {
+		for i := len(migrations) - 1; i >= 0 && n > 0; i-- {
+			if _, ok := applied[migrations[i].version]; !ok {
+				continue
+			}
+			if err := m.down(ctx, migrations[i]); err != nil {
+				return err
+			}
+			n--
+		}
+		return nil
+	})
+}
+
+func (m *migrator) Goto(ctx context.Context, version uint64) error {
+	return m.run(ctx, func(migrations []migration, applied map[uint64]appliedMigration) error {
+		if version > 0 && !hasVersion(migrations, version) {
+			return errors.NewWithCode(codes.CodeSQLMigration, "migration version %d does not exist", version)
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[migrations[i].version]; !ok || migrations[i].version <= version {
+				continue
+			}
+			if err := m.down(ctx, migrations[i]); err != nil {
+				return err
+			}
+		}
+
+		for _, mg := range migrations {
+			if _, ok := applied[mg.version]; ok || mg.version > version {
+				continue
+			}
+			if err := m.up(ctx, mg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *migrator) Status(ctx context.Context) ([]Status, error) {
+	var status []Status
+	err := m.read(ctx, func(migrations []migration, applied map[uint64]appliedMigration) error {
+		for _, mg := range migrations {
+			s := Status{Version: mg.version, Name: mg.name}
+			if a, ok := applied[mg.version]; ok {
+				s.Applied, s.AppliedAt = true, a.AppliedAt
+				delete(applied, mg.version)
+			}
+			status = append(status, s)
+		}
+
+		missing := make([]Status, 0, len(applied))
+		for _, a := range applied {
+			missing = append(missing, Status{Version: a.Version, Name: a.Name, Applied: true, AppliedAt: a.AppliedAt, Missing: true})
+		}
+		sort.Slice(missing, func(i, j int) bool { return missing[i].Version < missing[j].Version })
+
+		status = append(status, missing...)
+		return nil
+	})
+
+	return status, err
+}
+
+// run loads the migrations and the applied versions while holding the migration lock.
+// Migrations and the lock wait are not bound by the query timeout of the database.
+func (m *migrator) run(ctx context.Context, fn func(migrations []migration, applied map[uint64]appliedMigration) error) error {
+	ctx = sql.WithoutQueryTimeout(ctx)
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return m.read(ctx, fn)
+}
+
+// read loads the migrations and the applied versions.
+func (m *migrator) read(ctx context.Context, fn func(migrations []migration, applied map[uint64]appliedMigration) error) error {
+	ctx = sql.WithoutQueryTimeout(ctx)
+	migrations, err := loadMigrations(m.source())
+	if err != nil {
+		return err
+	}
+
+	if err := m.createTable(ctx); err != nil {
+		return err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	return fn(migrations, applied)
+}
+
+func (m *migrator) source() fs.FS {
+	if m.cfg.FS != nil {
+		return m.cfg.FS
+	}
+	return os.DirFS(m.cfg.Dir)
+}
+
+type appliedMigration struct {
+	Version   uint64    `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+func (m *migrator) createTable(ctx context.Context) error {
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)", m.cfg.Table)
+	if _, err := m.db.Leader().Exec(ctx, "migrate create table", query); err != nil {
+		return errors.WrapWithCode(err, codes.CodeSQLMigration, "create migration table")
+	}
+	return nil
+}
+
+func (m *migrator) applied(ctx context.Context) (map[uint64]appliedMigration, error) {
+	var rows []appliedMigration
+	query := fmt.Sprintf("SELECT version, name, applied_at FROM %s", m.cfg.Table)
+	if err := m.db.Leader().Select(ctx, "migrate applied versions", query, &rows); err != nil {
+		return nil, errors.WrapWithCode(err, codes.CodeSQLMigration, "read applied migrations")
+	}
+
+	applied := make(map[uint64]appliedMigration, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r
+	}
+
+	return applied, nil
+}
+
+func (m *migrator) up(ctx context.Context, mg migration) error {
+	start := time.Now()
+	err := m.db.Leader().ExecuteInTransaction(ctx, "migrate up", sql.TxOptions{}, func(tx sql.CommandTx) error {
+		if _, err := tx.Exec("migrate up", mg.up); err != nil {
+			return err
+		}
+
+		query := m.db.Rebind(fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)", m.cfg.Table))
+		_, err := tx.Exec("migrate insert version", query, mg.version, mg.name, time.Now().UTC())
+		return err
+	})
+	if err != nil {
+		return errors.WrapWithCode(err, codes.CodeSQLMigration, "migrate up %d_%s", mg.version, mg.name)
+	}
+
+	m.log.Info(ctx, fmt.Sprintf(migrateUpLogMessage, mg.version, mg.name, time.Since(start)))
+	return nil
+}
+
+func (m *migrator) down(ctx context.Context, mg migration) error {
+	if mg.down == "" {
+		return errors.NewWithCode(codes.CodeSQLMigration, "migration %d_%s has no down file", mg.version, mg.name)
+	}
+
+	start := time.Now()
+	err := m.db.Leader().ExecuteInTransaction(ctx, "migrate down", sql.TxOptions{}, func(tx sql.CommandTx) error {
+		if _, err := tx.Exec("migrate down", mg.down); err != nil {
+			return err
+		}
+
+		query := m.db.Rebind(fmt.Sprintf("DELETE FROM %s WHERE version = ?", m.cfg.Table))
+		_, err := tx.Exec("migrate delete version", query, mg.version)
+		return err
+	})
+	if err != nil {
+		return errors.WrapWithCode(err, codes.CodeSQLMigration, "migrate down %d_%s", mg.version, mg.name)
+	}
+
+	m.log.Info(ctx, fmt.Sprintf(migrateDownLogMessage, mg.version, mg.name, time.Since(start)))
+	return nil
+}
+
+func hasVersion(migrations []migration, version uint64) bool {
+	for _, mg := range migrations {
+		if mg.version == version {
+			return true
+		}
+	}
+	return false
+}
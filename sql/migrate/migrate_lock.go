@@ -0,0 +1,78 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/reyhanmichiels/go-pkg/v2/sql"
+)
+
+const (
+	// mysqlLockTimeout is how many seconds GET_LOCK waits for another instance to finish migrating.
+	mysqlLockTimeout = 600
+
+	releaseLockLogMessage = "SQL: failed to release migration lock %s: %s"
+)
+
+// lock takes an advisory lock on a dedicated transaction, which keeps it on one connection until unlock is called.
+// Drivers without advisory locks are not locked.
+func (m *migrator) lock(ctx context.Context) (func(), error) {
+	switch m.db.Driver() {
+	case "postgres":
+		return m.lockPostgres(ctx)
+	case "mysql":
+		return m.lockMySQL(ctx)
+	default:
+		return func() {}, nil
+	}
+}
+
+func (m *migrator) lockPostgres(ctx context.Context) (func(), error) {
+	tx, err := m.db.Leader().BeginTx(ctx, "migrate lock", sql.TxOptions{})
+	if err != nil {
+		return nil, errors.WrapWithCode(err, codes.CodeSQLMigrationLocked, "begin migration lock")
+	}
+
+	// the lock is released together with the transaction
+	if _, err := tx.Exec("migrate lock", "SELECT pg_advisory_xact_lock($1)", lockID(m.cfg.LockKey)); err != nil {
+		tx.Rollback()
+		return nil, errors.WrapWithCode(err, codes.CodeSQLMigrationLocked, "take migration lock %s", m.cfg.LockKey)
+	}
+
+	return tx.Rollback, nil
+}
+
+func (m *migrator) lockMySQL(ctx context.Context) (func(), error) {
+	tx, err := m.db.Leader().BeginTx(ctx, "migrate lock", sql.TxOptions{})
+	if err != nil {
+		return nil, errors.WrapWithCode(err, codes.CodeSQLMigrationLocked, "begin migration lock")
+	}
+
+	var locked int
+	if err := tx.Get("migrate lock", "SELECT COALESCE(GET_LOCK(?, ?), 0)", &locked, m.cfg.LockKey, mysqlLockTimeout); err != nil {
+		tx.Rollback()
+		return nil, errors.WrapWithCode(err, codes.CodeSQLMigrationLocked, "take migration lock %s", m.cfg.LockKey)
+	}
+	if locked != 1 {
+		tx.Rollback()
+		return nil, errors.NewWithCode(codes.CodeSQLMigrationLocked, "timed out waiting for migration lock %s", m.cfg.LockKey)
+	}
+
+	// GET_LOCK is held by the session, not the transaction, so it must be released explicitly.
+	return func() {
+		defer tx.Rollback()
+		if _, err := tx.Exec("migrate unlock", "SELECT RELEASE_LOCK(?)", m.cfg.LockKey); err != nil {
+			m.log.Error(ctx, fmt.Sprintf(releaseLockLogMessage, m.cfg.LockKey, err.Error()))
+		}
+	}, nil
+}
+
+// lockID maps the lock key to the bigint key of postgres advisory locks.
+func lockID(key string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64()) // nolint:gosec
+}
@@ -0,0 +1,80 @@
+package migrate
+
+import (
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+)
+
+// fileNameRegex matches migration files named `<version>_<name>.<up|down>.sql`, e.g. `20240101120000_create_users.up.sql`.
+var fileNameRegex = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type migration struct {
+	version uint64
+	name    string
+	up      string
+	down    string
+}
+
+// loadMigrations reads the migration files at the root of fsys, sorted by version ascending.
+// Files not matching the naming convention are ignored.
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.NewWithCode(codes.CodeSQLMigration, "read migrations: %s", err.Error())
+	}
+
+	byVersion := map[uint64]*migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		match := fileNameRegex.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, errors.NewWithCode(codes.CodeSQLMigration, "invalid migration version %s: %s", e.Name(), err.Error())
+		}
+
+		content, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, errors.NewWithCode(codes.CodeSQLMigration, "read migration %s: %s", e.Name(), err.Error())
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: match[2]}
+			byVersion[version] = m
+		} else if m.name != match[2] {
+			return nil, errors.NewWithCode(codes.CodeSQLMigration, "duplicate migration version %d: %s and %s", version, m.name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, errors.NewWithCode(codes.CodeSQLMigration, "migration %d_%s has no up file", m.version, m.name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
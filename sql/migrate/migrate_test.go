@@ -0,0 +1,142 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/reyhanmichiels/go-pkg/v2/sql"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_loadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []migration
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"2_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD email TEXT")},
+				"1_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INT)")},
+				"1_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+				"README.md":               {Data: []byte("ignored")},
+			},
+			want: []migration{
+				{version: 1, name: "create_users", up: "CREATE TABLE users (id INT)", down: "DROP TABLE users"},
+				{version: 2, name: "add_email", up: "ALTER TABLE users ADD email TEXT"},
+			},
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"1_create_users.up.sql":  {Data: []byte("CREATE TABLE users (id INT)")},
+				"1_create_orders.up.sql": {Data: []byte("CREATE TABLE orders (id INT)")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing up file",
+			fsys: fstest.MapFS{
+				"1_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadMigrations(tt.fsys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_migrator_Up(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, name, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "name", "applied_at"}).AddRow(1, "create_users", time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE users ADD email TEXT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations \(version, name, applied_at\) VALUES \(\$1, \$2, \$3\)`).
+		WithArgs(2, "add_email", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	db := sql.Init(sql.Config{
+		Driver: "postgres",
+		Leader: sql.ConnConfig{MockDB: mockDB},
+	}, logger)
+
+	m := Init(Config{
+		FS: fstest.MapFS{
+			"1_create_users.up.sql": {Data: []byte("CREATE TABLE users (id INT)")},
+			"2_add_email.up.sql":    {Data: []byte("ALTER TABLE users ADD email TEXT")},
+		},
+	}, db, logger)
+
+	assert.NoError(t, m.Up(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_migrator_Status(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// no lock is taken, so a running migration does not block the status
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, name, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "name", "applied_at"}).
+			AddRow(1, "create_users", appliedAt).
+			AddRow(5, "add_phone", appliedAt).
+			AddRow(3, "add_role", appliedAt).
+			AddRow(4, "add_status", appliedAt))
+
+	db := sql.Init(sql.Config{
+		Driver: "postgres",
+		Leader: sql.ConnConfig{MockDB: mockDB},
+	}, logger)
+
+	m := Init(Config{
+		FS: fstest.MapFS{
+			"1_create_users.up.sql": {Data: []byte("CREATE TABLE users (id INT)")},
+			"2_add_email.up.sql":    {Data: []byte("ALTER TABLE users ADD email TEXT")},
+		},
+	}, db, logger)
+
+	got, err := m.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Status{
+		{Version: 1, Name: "create_users", Applied: true, AppliedAt: appliedAt},
+		{Version: 2, Name: "add_email"},
+		{Version: 3, Name: "add_role", Applied: true, AppliedAt: appliedAt, Missing: true},
+		{Version: 4, Name: "add_status", Applied: true, AppliedAt: appliedAt, Missing: true},
+		{Version: 5, Name: "add_phone", Applied: true, AppliedAt: appliedAt, Missing: true},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
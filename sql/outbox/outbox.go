@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/reyhanmichiels/go-pkg/v2/log"
+	"github.com/reyhanmichiels/go-pkg/v2/operator"
+	"github.com/reyhanmichiels/go-pkg/v2/sql"
+)
+
+const (
+	defaultTable          = "outbox"
+	defaultPollInterval   = time.Second
+	defaultBatchSize      = 100
+	defaultMaxAttempts    = 10
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = 5 * time.Minute
+
+	relayFailedLogMessage    = "OUTBOX: relay failed, with error: %s"
+	publishFailedLogMessage  = "OUTBOX: failed to publish message %d to %s/%s, attempt %d, with error: %s"
+	publishDroppedLogMessage = "OUTBOX: giving up on message %d to %s/%s after %d attempts, with error: %s"
+)
+
+// Publisher publishes a message, it is implemented by rabbitmq.Interface.
+type Publisher interface {
+	Publish(ctx context.Context, exchangeName string, routingKey string, body string) error
+}
+
+// Interface writes messages to the outbox table, so they are only published when the transaction
+// that wrote them commits. The relay publishes them afterwards, at least once and not necessarily in order.
+type Interface interface {
+	// Enqueue writes a message with the transaction in ctx, see sql.Interface.Transaction.
+	// Without a transaction the message is written right away.
+	Enqueue(ctx context.Context, exchange string, routingKey string, body string) error
+	Stop()
+}
+
+// Config configures the outbox. The table must exist, e.g. created by a migration, on postgres:
+//
+//	CREATE TABLE outbox (
+//		id BIGSERIAL PRIMARY KEY,
+//		exchange VARCHAR(255) NOT NULL,
+//		routing_key VARCHAR(255) NOT NULL,
+//		body TEXT NOT NULL,
+//		attempts INT NOT NULL DEFAULT 0,
+//		last_error TEXT,
+//		created_at TIMESTAMP NOT NULL,
+//		next_attempt_at TIMESTAMP NOT NULL,
+//		sent_at TIMESTAMP
+//	);
+//	CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at) WHERE sent_at IS NULL;
+//
+// On mysql use BIGINT AUTO_INCREMENT for id and an index on (sent_at, next_attempt_at).
+type Config struct {
+	Table string // Table defaults to outbox.
+	Relay RelayConfig
+}
+
+// RelayConfig enables the worker that publishes pending messages. Several instances can relay at the same time,
+// rows are locked with FOR UPDATE SKIP LOCKED which needs postgres 9.5 or mysql 8.
+type RelayConfig struct {
+	Enabled      bool
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxAttempts is how often a message is published before it is left in the table unsent, defaults to 10.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type outbox struct {
+	cfg       Config
+	db        sql.Interface
+	publisher Publisher
+	log       log.Interface
+	endOnce   *sync.Once
+	stop      chan struct{}
+	wg        *sync.WaitGroup
+}
+
+// Init creates the outbox and calls log.Fatal when the config is invalid, see InitWithError.
+func Init(cfg Config, db sql.Interface, publisher Publisher, log log.Interface) Interface {
+	o, err := InitWithError(cfg, db, publisher, log)
+	if err != nil {
+		log.Fatal(context.Background(), fmt.Sprintf("[FATAL] %s", err.Error()))
+	}
+
+	return o
+}
+
+// InitWithError creates the outbox like Init, but returns an error when the config is invalid.
+// publisher is only used by the relay and may be nil when it is disabled.
+func InitWithError(cfg Config, db sql.Interface, publisher Publisher, log log.Interface) (Interface, error) {
+	if cfg.Relay.Enabled && publisher == nil {
+		return nil, errors.NewWithCode(codes.CodeInvalidValue, "outbox relay is enabled without a publisher")
+	}
+
+	cfg.Table = operator.Ternary(cfg.Table == "", defaultTable, cfg.Table)
+	cfg.Relay.PollInterval = operator.Ternary(cfg.Relay.PollInterval <= 0, defaultPollInterval, cfg.Relay.PollInterval)
+	cfg.Relay.BatchSize = operator.Ternary(cfg.Relay.BatchSize <= 0, defaultBatchSize, cfg.Relay.BatchSize)
+	cfg.Relay.MaxAttempts = operator.Ternary(cfg.Relay.MaxAttempts <= 0, defaultMaxAttempts, cfg.Relay.MaxAttempts)
+	cfg.Relay.InitialBackoff = operator.Ternary(cfg.Relay.InitialBackoff <= 0, defaultInitialBackoff, cfg.Relay.InitialBackoff)
+	cfg.Relay.MaxBackoff = operator.Ternary(cfg.Relay.MaxBackoff <= 0, defaultMaxBackoff, cfg.Relay.MaxBackoff)
+
+	o := &outbox{
+		cfg:       cfg,
+		db:        db,
+		publisher: publisher,
+		log:       log,
+		endOnce:   &sync.Once{},
+		stop:      make(chan struct{}),
+		wg:        &sync.WaitGroup{},
+	}
+
+	o.startRelay()
+
+	return o, nil
+}
+
+func (o *outbox) Enqueue(ctx context.Context, exchange string, routingKey string, body string) error {
+	now := time.Now().UTC()
+	query := o.db.Rebind(fmt.Sprintf("INSERT INTO %s (exchange, routing_key, body, attempts, created_at, next_attempt_at) VALUES (?, ?, ?, 0, ?, ?)", o.cfg.Table))
+
+	_, err := o.db.Exec(ctx, "outbox enqueue", query, exchange, routingKey, body, now, now)
+	return err
+}
+
+// Stop stops the relay, waiting for the batch in progress.
+func (o *outbox) Stop() {
+	o.endOnce.Do(func() {
+		close(o.stop)
+		o.wg.Wait()
+	})
+}
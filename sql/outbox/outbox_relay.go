@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/reyhanmichiels/go-pkg/v2/sql"
+)
+
+type message struct {
+	ID         int64  `db:"id"`
+	Exchange   string `db:"exchange"`
+	RoutingKey string `db:"routing_key"`
+	Body       string `db:"body"`
+	Attempts   int    `db:"attempts"`
+}
+
+// startRelay publishes pending messages until Stop is called. A full batch is followed by the next one right away.
+func (o *outbox) startRelay() {
+	if !o.cfg.Relay.Enabled {
+		return
+	}
+
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+
+		// a batch is published within its transaction, which is not bound by the query timeout of the database
+		ctx := sql.WithoutQueryTimeout(context.Background())
+		for {
+			n, err := o.relay(ctx)
+			if err != nil {
+				o.log.Error(ctx, fmt.Sprintf(relayFailedLogMessage, err))
+			}
+
+			wait := o.cfg.Relay.PollInterval
+			if err == nil && n >= o.cfg.Relay.BatchSize {
+				wait = 0
+			}
+
+			select {
+			case <-o.stop:
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// relay publishes one batch of pending messages and returns how many it picked up.
+func (o *outbox) relay(ctx context.Context) (int, error) {
+	var picked int
+	err := o.db.Transaction(ctx, "outbox relay", sql.TxOptions{}, func(ctx context.Context) error {
+		now := time.Now().UTC()
+
+		var messages []message
+		if err := o.db.Select(ctx, "outbox select pending", o.selectPendingQuery(), &messages, o.cfg.Relay.MaxAttempts, now, o.cfg.Relay.BatchSize); err != nil {
+			return err
+		}
+		picked = len(messages)
+
+		for _, m := range messages {
+			if err := o.publish(ctx, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return picked, err
+}
+
+func (o *outbox) selectPendingQuery() string {
+	lock := " FOR UPDATE SKIP LOCKED"
+	if o.db.Driver() == "sqlite" {
+		// sqlite has a single writer, the transaction already excludes other relays
+		lock = ""
+	}
+
+	return o.db.Rebind(fmt.Sprintf("SELECT id, exchange, routing_key, body, attempts FROM %s "+
+		"WHERE sent_at IS NULL AND attempts < ? AND next_attempt_at <= ? ORDER BY id LIMIT ?%s", o.cfg.Table, lock))
+}
+
+// publish publishes m and records the result. A failed publish is not an error of the batch, it is retried later.
+func (o *outbox) publish(ctx context.Context, m message) error {
+	now := time.Now().UTC()
+
+	pubErr := o.publisher.Publish(ctx, m.Exchange, m.RoutingKey, m.Body)
+	if pubErr == nil {
+		query := o.db.Rebind(fmt.Sprintf("UPDATE %s SET sent_at = ? WHERE id = ?", o.cfg.Table))
+		_, err := o.db.Exec(ctx, "outbox mark sent", query, now, m.ID)
+		return err
+	}
+
+	attempts := m.Attempts + 1
+	if attempts >= o.cfg.Relay.MaxAttempts {
+		o.log.Error(ctx, fmt.Sprintf(publishDroppedLogMessage, m.ID, m.Exchange, m.RoutingKey, attempts, pubErr))
+	} else {
+		o.log.Warn(ctx, fmt.Sprintf(publishFailedLogMessage, m.ID, m.Exchange, m.RoutingKey, attempts, pubErr))
+	}
+
+	query := o.db.Rebind(fmt.Sprintf("UPDATE %s SET attempts = ?, last_error = ?, next_attempt_at = ? WHERE id = ?", o.cfg.Table))
+	_, err := o.db.Exec(ctx, "outbox mark failed", query, attempts, pubErr.Error(), now.Add(o.backoff(attempts)), m.ID)
+	return err
+}
+
+// backoff doubles the wait after every failed attempt up to MaxBackoff.
+func (o *outbox) backoff(attempts int) time.Duration {
+	d := o.cfg.Relay.InitialBackoff << (attempts - 1)
+	if d <= 0 || d > o.cfg.Relay.MaxBackoff {
+		return o.cfg.Relay.MaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,102 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/reyhanmichiels/go-pkg/v2/sql"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type publisherFunc func(ctx context.Context, exchangeName string, routingKey string, body string) error
+
+func (f publisherFunc) Publish(ctx context.Context, exchangeName string, routingKey string, body string) error {
+	return f(ctx, exchangeName, routingKey, body)
+}
+
+func Test_outbox_Enqueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO outbox \(exchange, routing_key, body, attempts, created_at, next_attempt_at\) VALUES \(\$1, \$2, \$3, 0, \$4, \$5\)`).
+		WithArgs("orders", "order.created", `{"id":1}`, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	db := sql.Init(sql.Config{Driver: "postgres", Leader: sql.ConnConfig{MockDB: mockDB}}, logger)
+	o := Init(Config{}, db, nil, logger)
+	defer o.Stop()
+
+	err := db.Transaction(context.Background(), "create order", sql.TxOptions{}, func(ctx context.Context) error {
+		if _, err := db.Exec(ctx, "insert order", "INSERT INTO orders (id) VALUES (1)"); err != nil {
+			return err
+		}
+		return o.Enqueue(ctx, "orders", "order.created", `{"id":1}`)
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_outbox_relay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Warn(gomock.Any(), gomock.Any()).Times(1)
+
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, exchange, routing_key, body, attempts FROM outbox WHERE sent_at IS NULL AND attempts < \$1 AND next_attempt_at <= \$2 ORDER BY id LIMIT \$3 FOR UPDATE SKIP LOCKED`).
+		WithArgs(10, sqlmock.AnyArg(), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "exchange", "routing_key", "body", "attempts"}).
+			AddRow(1, "orders", "order.created", `{"id":1}`, 0).
+			AddRow(2, "orders", "order.paid", `{"id":1}`, 2))
+	mock.ExpectExec(`UPDATE outbox SET sent_at = \$1 WHERE id = \$2`).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE outbox SET attempts = \$1, last_error = \$2, next_attempt_at = \$3 WHERE id = \$4`).
+		WithArgs(3, "channel closed", sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	publisher := publisherFunc(func(_ context.Context, _ string, routingKey string, _ string) error {
+		if routingKey == "order.paid" {
+			return fmt.Errorf("channel closed")
+		}
+		return nil
+	})
+
+	db := sql.Init(sql.Config{Driver: "postgres", Leader: sql.ConnConfig{MockDB: mockDB}}, logger)
+	o := Init(Config{}, db, publisher, logger).(*outbox)
+	defer o.Stop()
+
+	picked, err := o.relay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, picked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_outbox_InitWithError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockDB, _, _ := sqlmock.New()
+	db := sql.Init(sql.Config{Driver: "postgres", Leader: sql.ConnConfig{MockDB: mockDB}}, logger)
+
+	_, err := InitWithError(Config{Relay: RelayConfig{Enabled: true}}, db, nil, logger)
+	assert.Error(t, err)
+}
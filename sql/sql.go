@@ -4,170 +4,339 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/reyhanmichiels/go-pkg/v2/log"
 )
 
 var ErrNotFound = sql.ErrNoRows
 
-const (
-	failedConnectDBMessage = "[FATAL] cannot connect to db %s leader: %s on port %d, with error: %s"
-)
-
-type txKey struct{} // txKey is a context key for the transaction.
+type txKey struct{}        // txKey is a context key for the transaction.
+type savepointKey struct{} // savepointKey is a context key for the savepoint depth of a nested transaction.
 
 type Interface interface {
 	Leader() Command
 	Follower() Command
+	Health() HealthStatus
+	Stats() Stats
 	Stop()
 
+	Driver() string
 	Rebind(query string) string
 
-	QueryRow(ctx context.Context, name string, query string, args ...interface{}) (*sqlx.Row, error)
-	Query(ctx context.Context, name string, query string, args ...interface{}) (*sqlx.Rows, error)
+	// QueryRow and Query return *Row and *Rows, which wrap *sqlx.Row and *sqlx.Rows to release the query
+	// timeout; they returned the sqlx types before.
+	QueryRow(ctx context.Context, name string, query string, args ...interface{}) (*Row, error)
+	Query(ctx context.Context, name string, query string, args ...interface{}) (*Rows, error)
 	Get(ctx context.Context, name string, query string, dest interface{}, args ...interface{}) error
+	Select(ctx context.Context, name string, query string, dest interface{}, args ...interface{}) error
 
 	Prepare(ctx context.Context, name string, query string) (CommandStmt, error)
 	PrepareNamed(ctx context.Context, name string, query string) (NamedCommandStmt, error)
 	NamedExec(ctx context.Context, name string, query string, args interface{}) (sql.Result, error)
 	Exec(ctx context.Context, name string, query string, args ...interface{}) (sql.Result, error)
+	BulkInsert(ctx context.Context, name string, table string, rows interface{}) (int64, error)
+	Upsert(ctx context.Context, name string, table string, rows interface{}, opt UpsertOptions) (int64, error)
 	Transaction(ctx context.Context, name string, txOpts TxOptions, f func(context.Context) error) error
 }
 
 type sqlDB struct {
-	endOnce  *sync.Once
-	leader   Command
-	follower Command
-	cfg      Config
-	log      log.Interface
+	endOnce   *sync.Once
+	leader    *node
+	followers []*node
+	balancer  balancer
+	cfg       Config
+	log       log.Interface
+	stop      chan struct{}
+	wg        *sync.WaitGroup
 }
 
 type Config struct {
-	LogQuery bool
-	Driver   string
-	Leader   ConnConfig
-	Follower ConnConfig
+	LogQuery   bool
+	LogOptions LogOptions
+	// Driver is postgres, mysql or sqlite. The sqlite driver is not linked in by this package, an application
+	// using it must import a pure-Go driver registered as "sqlite", e.g. `import _ "modernc.org/sqlite"`,
+	// otherwise Init fails with an unknown driver error.
+	Driver      string
+	Leader      ConnConfig
+	Follower    ConnConfig
+	Followers   []ConnConfig // Followers takes precedence over Follower when it is not empty.
+	LoadBalance LoadBalanceStrategy
+	HealthCheck HealthCheckConfig
+	// StickyLeaderWindow is how long reads stay on the leader after a write on a context
+	// created with WithReadYourWrites. Zero keeps them on the leader for the rest of the context.
+	StickyLeaderWindow time.Duration
+	// SlowQueryThreshold logs statements taking at least this long at warn level, zero disables it.
+	SlowQueryThreshold time.Duration
+	QueryHooks         []QueryHook
+	// QueryTimeout bounds every statement run through Command and CommandTx, zero disables it.
+	// QueryTimeouts overrides it by statement name, a zero override disables it for that name.
+	// Statements run with a context from WithoutQueryTimeout are exempt, migrate and the outbox relay use it.
+	QueryTimeout  time.Duration
+	QueryTimeouts map[string]time.Duration
+	PoolMonitor   PoolMonitorConfig
+	Connect       ConnectConfig
+	StmtCache     StmtCacheConfig
 }
 
+// ConnConfig is the connection of a node. A follower is only connected to when it sets DSN or Host
+// and does not point to the leader: its DSN differs from the leader DSN, or without DSN its Host or Port differs.
 type ConnConfig struct {
 	Host     string
 	Port     int
 	DB       string
 	User     string
 	Password string
-	SSL      bool
+	SSL      bool // SSL is used when TLS.Mode is not set, as sslmode=require for postgres and tls=true for mysql.
+	TLS      TLSConfig
 	Schema   string
 	Options  ConnOptions
-	MockDB   *sql.DB
+	Weight   int // Weight is only used by the weighted load balance strategy.
+	// DSN is passed to the driver as is, instead of the one built from the fields above.
+	// It allows drivers that are not supported out of the box, their driver must be imported by the application.
+	DSN string
+	// ConnectTimeout bounds establishing a connection, it is rounded up to whole seconds on postgres.
+	ConnectTimeout  time.Duration
+	ApplicationName string
+	// Params are extra driver specific connection parameters, they take precedence over the ones set from the fields above.
+	Params map[string]string
+	MockDB *sql.DB
 }
 
 type ConnOptions struct {
 	MaxLifeTime time.Duration
+	MaxIdleTime time.Duration
 	MaxIdle     int
 	MaxOpen     int
 }
 
+// Init connects to the database and calls log.Fatal when it cannot, see InitWithError.
 func Init(cfg Config, log log.Interface) Interface {
+	db, err := InitWithError(cfg, log)
+	if err != nil {
+		log.Fatal(context.Background(), fmt.Sprintf("[FATAL] %s", err.Error()))
+	}
+
+	return db
+}
+
+// InitWithError connects to the database like Init, but returns an error instead of exiting when a node
+// cannot be reached within Config.Connect.Timeout. With Config.Connect.Lazy it only fails on invalid configuration.
+func InitWithError(cfg Config, log log.Interface) (Interface, error) {
 	sqlDB := sqlDB{
-		endOnce: &sync.Once{},
-		cfg:     cfg,
-		log:     log,
+		endOnce:  &sync.Once{},
+		cfg:      cfg,
+		log:      log,
+		stop:     make(chan struct{}),
+		wg:       &sync.WaitGroup{},
+		balancer: initBalancer(cfg.LoadBalance),
 	}
 
-	sqlDB.initDB()
+	if err := sqlDB.initDB(); err != nil {
+		sqlDB.Stop()
+		return nil, err
+	}
+
+	sqlDB.startHealthCheck()
+	sqlDB.startPoolMonitor()
 
-	return &sqlDB
+	return &sqlDB, nil
 }
 
 func (s *sqlDB) Leader() Command {
-	return s.leader
+	return s.leader.command
 }
 
+// Follower returns a healthy follower picked by the configured load balance strategy. It falls back to
+// the leader when no follower is configured or when every follower has been marked unhealthy by the health checker.
 func (s *sqlDB) Follower() Command {
-	return s.follower
+	healthy := make([]*node, 0, len(s.followers))
+	for _, f := range s.followers {
+		if f.isHealthy() {
+			healthy = append(healthy, f)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return s.leader.command
+	}
+
+	return s.balancer.next(healthy).command
 }
 
 func (s *sqlDB) Stop() {
 	s.endOnce.Do(func() {
+		close(s.stop)
+		s.wg.Wait()
+
 		ctx := context.Background()
 		if s.leader != nil {
-			if err := s.leader.Close(); err != nil {
+			if err := s.leader.command.Close(); err != nil {
 				s.log.Error(ctx, err)
 			}
 		}
 
-		if s.follower != nil {
-			if err := s.follower.Close(); err != nil {
+		for _, f := range s.followers {
+			if err := f.command.Close(); err != nil {
 				s.log.Error(ctx, err)
 			}
 		}
 	})
 }
 
+func (s *sqlDB) Driver() string {
+	return s.cfg.Driver
+}
+
 func (s *sqlDB) Rebind(query string) string {
-	return s.leader.Rebind(query)
+	return s.leader.command.Rebind(query)
 }
 
-func (s *sqlDB) QueryRow(ctx context.Context, name string, query string, args ...interface{}) (*sqlx.Row, error) {
-	return s.follower.QueryRow(ctx, name, query, args...)
+func (s *sqlDB) QueryRow(ctx context.Context, name string, query string, args ...interface{}) (*Row, error) {
+	if tx, ok := s.getTx(ctx); ok {
+		return tx.QueryRow(name, query, args...)
+	}
+	return s.reader(ctx).QueryRow(ctx, name, query, args...)
 }
 
-func (s *sqlDB) Query(ctx context.Context, name string, query string, args ...interface{}) (*sqlx.Rows, error) {
-	return s.follower.Query(ctx, name, query, args...)
+func (s *sqlDB) Query(ctx context.Context, name string, query string, args ...interface{}) (*Rows, error) {
+	if tx, ok := s.getTx(ctx); ok {
+		return tx.Query(name, query, args...)
+	}
+	return s.reader(ctx).Query(ctx, name, query, args...)
 }
 
 func (s *sqlDB) Get(ctx context.Context, name string, query string, dest interface{}, args ...interface{}) error {
-	return s.follower.Get(ctx, name, query, dest, args...)
+	if tx, ok := s.getTx(ctx); ok {
+		return tx.Get(name, query, dest, args...)
+	}
+	return s.reader(ctx).Get(ctx, name, query, dest, args...)
+}
+
+func (s *sqlDB) Select(ctx context.Context, name string, query string, dest interface{}, args ...interface{}) error {
+	if tx, ok := s.getTx(ctx); ok {
+		return tx.Select(name, query, dest, args...)
+	}
+	return s.reader(ctx).Select(ctx, name, query, dest, args...)
 }
 
 func (s *sqlDB) Prepare(ctx context.Context, name string, query string) (CommandStmt, error) {
 	if tx, ok := s.getTx(ctx); ok {
 		return tx.Prepare(name, query)
 	}
-	return s.leader.Prepare(ctx, name, query)
+	return s.leader.command.Prepare(ctx, name, query)
 }
 
 func (s *sqlDB) PrepareNamed(ctx context.Context, name string, query string) (NamedCommandStmt, error) {
 	if tx, ok := s.getTx(ctx); ok {
 		return tx.PrepareNamed(name, query)
 	}
-	return s.leader.PrepareNamed(ctx, name, query)
+	return s.leader.command.PrepareNamed(ctx, name, query)
 }
 
 func (s *sqlDB) NamedExec(ctx context.Context, name string, query string, args interface{}) (sql.Result, error) {
 	if tx, ok := s.getTx(ctx); ok {
 		return tx.NamedExec(name, query, args)
 	}
-	return s.leader.NamedExec(ctx, name, query, args)
+
+	res, err := s.leader.command.NamedExec(ctx, name, query, args)
+	if err == nil {
+		s.markWrite(ctx)
+	}
+	return res, err
 }
 
 func (s *sqlDB) Exec(ctx context.Context, name string, query string, args ...interface{}) (sql.Result, error) {
 	if tx, ok := s.getTx(ctx); ok {
 		return tx.Exec(name, query, args...)
 	}
-	return s.leader.Exec(ctx, name, query, args...)
+
+	res, err := s.leader.command.Exec(ctx, name, query, args...)
+	if err == nil {
+		s.markWrite(ctx)
+	}
+	return res, err
 }
 
 // Transaction executes a transaction. If the given function returns an error, the transaction
 // is rolled back. Otherwise, it is automatically committed before `Transaction()` returns.
+// When the context already carries a transaction, the outer transaction is reused and the
+// given function runs inside a savepoint instead, txOpts is ignored in that case.
+// The transaction is run again when it fails with an error that is retryable according to txOpts.Retry.
 func (s *sqlDB) Transaction(ctx context.Context, name string, txOpts TxOptions, f func(context.Context) error) error {
-	tx, err := s.leader.BeginTx(ctx, name, txOpts)
+	if tx, ok := s.getTx(ctx); ok {
+		return s.nestedTransaction(ctx, tx, f)
+	}
+
+	return retry(ctx, s.cfg.Driver, txOpts.Retry, func() error {
+		return s.transaction(ctx, name, txOpts, f)
+	})
+}
+
+func (s *sqlDB) transaction(ctx context.Context, name string, txOpts TxOptions, f func(context.Context) error) error {
+	tx, err := s.leader.command.BeginTx(ctx, name, txOpts)
 	if err != nil {
 		return err
 	}
-	c := context.WithValue(ctx, txKey{}, tx)
+	hooks := initTxHooks()
+	c := context.WithValue(context.WithValue(ctx, txKey{}, tx), txHooksKey{}, hooks)
 	err = f(c)
 	if err != nil {
 		tx.Rollback()
+		hooks.runRollback(ctx)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		hooks.runRollback(ctx)
 		return err
 	}
 
-	return tx.Commit()
+	s.markWrite(ctx)
+	hooks.runCommit(ctx)
+
+	return nil
+}
+
+// nestedTransaction runs f inside a savepoint of the given transaction. If f returns an error, only
+// the changes made since the savepoint are rolled back and the error is returned to the outer scope.
+func (s *sqlDB) nestedTransaction(ctx context.Context, tx CommandTx, f func(context.Context) error) error {
+	depth, _ := ctx.Value(savepointKey{}).(int)
+	depth++
+
+	savepoint := fmt.Sprintf("sp_%d", depth)
+	if err := tx.Savepoint(savepoint); err != nil {
+		return err
+	}
+
+	hooks := initTxHooks()
+	err := f(context.WithValue(context.WithValue(ctx, savepointKey{}, depth), txHooksKey{}, hooks))
+	if err != nil {
+		if rbErr := tx.RollbackToSavepoint(savepoint); rbErr != nil {
+			s.log.Error(ctx, rbErr)
+		}
+		hooks.runRollback(ctx)
+		return err
+	}
+
+	if err := tx.ReleaseSavepoint(savepoint); err != nil {
+		hooks.runRollback(ctx)
+		return err
+	}
+
+	if parent, ok := ctx.Value(txHooksKey{}).(*txHooks); ok {
+		parent.merge(hooks)
+	}
+
+	return nil
 }
 
 // getTx retrieves the transaction from the context.
@@ -176,80 +345,156 @@ func (s *sqlDB) getTx(ctx context.Context) (CommandTx, bool) {
 	return tx, ok
 }
 
-func (s *sqlDB) initDB() {
+func (s *sqlDB) initDB() error {
 	ctx := context.Background()
 
-	db := s.connect(true)
+	db, err := s.connect(roleLeader, s.cfg.Leader)
+	if err != nil {
+		return err
+	}
 	s.log.Info(ctx, fmt.Sprintf("SQL: [LEADER] driver=%s db=%s @%s:%v ssl=%v", s.cfg.Driver, s.cfg.Leader.DB, s.cfg.Leader.Host, s.cfg.Leader.Port, s.cfg.Leader.SSL))
 
-	s.leader = initCommand(db, s.log, s.cfg.LogQuery)
-	s.follower = s.leader
+	obs := initObserver(s.log, s.cfg)
+	timeout := initQueryTimeout(s.cfg)
+	s.leader = initNode(roleLeader, s.cfg.Leader, initCommand(db, s.log, obs, timeout, initStmtCache(s.cfg.StmtCache)))
 
-	if s.isFollowerEnabled() {
-		db = s.connect(false)
-		s.log.Info(ctx, fmt.Sprintf("SQL: [FOLLOWER] driver=%s db=%s @%s:%v ssl=%v", s.cfg.Driver, s.cfg.Follower.DB, s.cfg.Follower.Host, s.cfg.Follower.Port, s.cfg.Leader.SSL))
+	for _, conf := range s.followerConfigs() {
+		db, err = s.connect(roleFollower, conf)
+		if err != nil {
+			return err
+		}
+		s.log.Info(ctx, fmt.Sprintf("SQL: [FOLLOWER] driver=%s db=%s @%s:%v ssl=%v strategy=%s", s.cfg.Driver, conf.DB, conf.Host, conf.Port, conf.SSL, s.balancer.name()))
 
-		s.follower = initCommand(db, s.log, s.cfg.LogQuery)
+		s.followers = append(s.followers, initNode(roleFollower, conf, initCommand(db, s.log, obs, timeout, initStmtCache(s.cfg.StmtCache))))
 	}
+
+	return s.pingNodes()
 }
 
-func (s *sqlDB) connect(isLeader bool) *sqlx.DB {
-	conf := s.cfg.Leader
-	if !isLeader {
-		conf = s.cfg.Follower
+func (s *sqlDB) getURI(conf ConnConfig) (string, error) {
+	if conf.DSN != "" {
+		return conf.DSN, nil
 	}
 
-	if conf.MockDB != nil {
-		return sqlx.NewDb(s.cfg.Leader.MockDB, s.cfg.Driver)
+	switch s.cfg.Driver {
+	case "postgres":
+		return postgresURI(conf)
+	case "mysql":
+		return mysqlURI(conf)
+	case sqliteDriver:
+		return sqliteURI(conf), nil
+	default:
+		return "", fmt.Errorf(`DB Driver [%s] is not supported`, s.cfg.Driver)
 	}
+}
 
-	uri, err := s.getURI(conf)
-	if err != nil {
-		s.log.Fatal(context.Background(), fmt.Sprintf("[FATAL] cannot get URI for db %s leader: %s on port %d, with error: %s", conf.DB, conf.Host, conf.Port, err))
+func postgresURI(conf ConnConfig) (string, error) {
+	if conf.Schema == "" {
+		conf.Schema = "public"
 	}
 
-	sqlxDB, err := sqlx.Open(s.cfg.Driver, uri)
-	if err != nil {
-		s.log.Fatal(context.Background(), fmt.Sprintf(failedConnectDBMessage, conf.DB, conf.Host, conf.Port, err))
+	params := map[string]string{
+		"host":        conf.Host,
+		"port":        strconv.Itoa(conf.Port),
+		"user":        conf.User,
+		"password":    conf.Password,
+		"dbname":      conf.DB,
+		"search_path": conf.Schema,
+		"sslmode":     string(tlsMode("postgres", conf)),
+	}
+	optional := map[string]string{
+		"sslrootcert":      conf.TLS.CAFile,
+		"sslcert":          conf.TLS.CertFile,
+		"sslkey":           conf.TLS.KeyFile,
+		"application_name": conf.ApplicationName,
+	}
+	if conf.ConnectTimeout > 0 {
+		// connect_timeout is in whole seconds, and zero means wait indefinitely
+		optional["connect_timeout"] = strconv.Itoa(int(math.Ceil(conf.ConnectTimeout.Seconds())))
+	}
+	for k, v := range optional {
+		if v != "" {
+			params[k] = v
+		}
+	}
+	for k, v := range conf.Params {
+		params[k] = v
 	}
 
-	err = sqlxDB.Ping()
+	// the connection keys come first, so the DSN reads the same as before for the common case
+	keys := []string{"host", "port", "user", "password", "dbname", "search_path", "sslmode"}
+	extra := []string{}
+	for k := range params {
+		if !contains(keys, k) {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+
+	pairs := make([]string, 0, len(params))
+	for _, k := range append(keys, extra...) {
+		pairs = append(pairs, k+"="+quotePostgresParam(params[k]))
+	}
+
+	return strings.Join(pairs, " "), nil
+}
+
+// quotePostgresParam quotes a keyword/value connection parameter when it is empty or contains spaces, quotes or backslashes.
+func quotePostgresParam(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	return "'" + strings.ReplaceAll(v, "'", `\'`) + "'"
+}
+
+func mysqlURI(conf ConnConfig) (string, error) {
+	tlsParam, err := mysqlTLS(conf)
 	if err != nil {
-		s.log.Fatal(context.Background(), fmt.Sprintf(failedConnectDBMessage, conf.DB, conf.Host, conf.Port, err))
+		return "", err
 	}
 
-	sqlxDB.SetMaxOpenConns(conf.Options.MaxOpen)
-	sqlxDB.SetMaxIdleConns(conf.Options.MaxIdle)
-	sqlxDB.SetConnMaxLifetime(conf.Options.MaxLifeTime)
+	params := url.Values{}
+	params.Set("tls", tlsParam)
+	params.Set("parseTime", "true")
+	if conf.ConnectTimeout > 0 {
+		params.Set("timeout", conf.ConnectTimeout.String())
+	}
+	if conf.ApplicationName != "" {
+		params.Set("connectionAttributes", "program_name:"+conf.ApplicationName)
+	}
+	for k, v := range conf.Params {
+		params.Set(k, v)
+	}
 
-	return sqlxDB
+	return fmt.Sprintf("%s:%s@tcp(%s:%v)/%s?%s", conf.User, conf.Password, conf.Host, conf.Port, conf.DB, params.Encode()), nil
 }
 
-func (s *sqlDB) getURI(conf ConnConfig) (string, error) {
-	switch s.cfg.Driver {
-	case "postgres":
-		ssl := `disable`
-		if conf.SSL {
-			ssl = `require`
-		}
-		if conf.Schema == "" {
-			conf.Schema = "public"
-		}
-		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s search_path=%s sslmode=%s", conf.Host, conf.Port, conf.User, conf.Password, conf.DB, conf.Schema, ssl), nil
-	case "mysql":
-		ssl := `false`
-		if conf.SSL {
-			ssl = `true`
+// followerConfigs returns the followers to connect to, ignoring entries that point to the leader itself.
+func (s *sqlDB) followerConfigs() []ConnConfig {
+	confs := s.cfg.Followers
+	if len(confs) == 0 {
+		confs = []ConnConfig{s.cfg.Follower}
+	}
+
+	followers := []ConnConfig{}
+	for _, conf := range confs {
+		if s.isFollowerEnabled(conf) {
+			followers = append(followers, conf)
 		}
-		return fmt.Sprintf("%s:%s@tcp(%s:%v)/%s?tls=%s&parseTime=true", conf.User, conf.Password, conf.Host, conf.Port, conf.DB, ssl), nil
-	default:
-		return "", fmt.Errorf(`DB Driver [%s] is not supported`, s.cfg.Driver)
 	}
+
+	return followers
 }
 
-func (s *sqlDB) isFollowerEnabled() bool {
-	isHostNotEmpty := s.cfg.Follower.Host != ""
-	isHostDifferent := s.cfg.Follower.Host != s.cfg.Leader.Host && s.cfg.Follower.Port == s.cfg.Leader.Port
-	isPortDifferent := s.cfg.Follower.Host == s.cfg.Leader.Host && s.cfg.Follower.Port != s.cfg.Leader.Port
+func (s *sqlDB) isFollowerEnabled(conf ConnConfig) bool {
+	if conf.DSN != "" {
+		return conf.DSN != s.cfg.Leader.DSN
+	}
+
+	isHostNotEmpty := conf.Host != ""
+	isHostDifferent := conf.Host != s.cfg.Leader.Host
+	isPortDifferent := conf.Port != s.cfg.Leader.Port
 	return isHostNotEmpty && (isHostDifferent || isPortDifferent)
 }
@@ -0,0 +1,110 @@
+package sql
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+type LoadBalanceStrategy string
+
+// Load balance strategy used to pick a follower for read queries
+const (
+	LoadBalanceRoundRobin    LoadBalanceStrategy = "round_robin"
+	LoadBalanceRandom        LoadBalanceStrategy = "random"
+	LoadBalanceLeastInFlight LoadBalanceStrategy = "least_in_flight"
+	LoadBalanceWeighted      LoadBalanceStrategy = "weighted"
+)
+
+type balancer interface {
+	name() LoadBalanceStrategy
+	// next picks one of the given nodes, nodes is never empty.
+	next(nodes []*node) *node
+}
+
+// initBalancer returns the balancer for the given strategy, defaulting to round robin.
+func initBalancer(strategy LoadBalanceStrategy) balancer {
+	switch strategy {
+	case LoadBalanceRandom:
+		return &randomBalancer{}
+	case LoadBalanceLeastInFlight:
+		return &leastInFlightBalancer{}
+	case LoadBalanceWeighted:
+		return &weightedBalancer{}
+	default:
+		return &roundRobinBalancer{counter: &atomic.Uint64{}}
+	}
+}
+
+type roundRobinBalancer struct {
+	counter *atomic.Uint64
+}
+
+func (b *roundRobinBalancer) name() LoadBalanceStrategy {
+	return LoadBalanceRoundRobin
+}
+
+func (b *roundRobinBalancer) next(nodes []*node) *node {
+	i := b.counter.Add(1) - 1
+	return nodes[i%uint64(len(nodes))]
+}
+
+type randomBalancer struct{}
+
+func (b *randomBalancer) name() LoadBalanceStrategy {
+	return LoadBalanceRandom
+}
+
+func (b *randomBalancer) next(nodes []*node) *node {
+	return nodes[rand.Intn(len(nodes))] // nolint:gosec
+}
+
+// leastInFlightBalancer picks the node with the fewest connections in use,
+// which counts both running statements and rows that are still being read.
+type leastInFlightBalancer struct{}
+
+func (b *leastInFlightBalancer) name() LoadBalanceStrategy {
+	return LoadBalanceLeastInFlight
+}
+
+func (b *leastInFlightBalancer) next(nodes []*node) *node {
+	picked := nodes[0]
+	least := picked.command.Stats().InUse
+	for _, n := range nodes[1:] {
+		if inUse := n.command.Stats().InUse; inUse < least {
+			picked, least = n, inUse
+		}
+	}
+	return picked
+}
+
+// weightedBalancer picks a node randomly, proportional to ConnConfig.Weight.
+// Nodes without a positive weight are treated as weight 1.
+type weightedBalancer struct{}
+
+func (b *weightedBalancer) name() LoadBalanceStrategy {
+	return LoadBalanceWeighted
+}
+
+func (b *weightedBalancer) next(nodes []*node) *node {
+	total := 0
+	for _, n := range nodes {
+		total += n.weight()
+	}
+
+	r := rand.Intn(total) // nolint:gosec
+	for _, n := range nodes {
+		r -= n.weight()
+		if r < 0 {
+			return n
+		}
+	}
+
+	return nodes[len(nodes)-1]
+}
+
+func (n *node) weight() int {
+	if n.conf.Weight <= 0 {
+		return 1
+	}
+	return n.conf.Weight
+}
@@ -0,0 +1,62 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sql_roundRobinBalancer(t *testing.T) {
+	nodes := []*node{
+		initNode(roleFollower, ConnConfig{Host: "follower-1"}, nil),
+		initNode(roleFollower, ConnConfig{Host: "follower-2"}, nil),
+		initNode(roleFollower, ConnConfig{Host: "follower-3"}, nil),
+	}
+
+	b := initBalancer(LoadBalanceRoundRobin)
+
+	got := []string{}
+	for i := 0; i < 4; i++ {
+		got = append(got, b.next(nodes).conf.Host)
+	}
+
+	assert.Equal(t, []string{"follower-1", "follower-2", "follower-3", "follower-1"}, got)
+}
+
+func Test_sql_weightedBalancer(t *testing.T) {
+	nodes := []*node{
+		initNode(roleFollower, ConnConfig{Host: "follower-1", Weight: 0}, nil),
+		initNode(roleFollower, ConnConfig{Host: "follower-2", Weight: 3}, nil),
+	}
+
+	b := initBalancer(LoadBalanceWeighted)
+
+	got := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		got[b.next(nodes).conf.Host]++
+	}
+
+	assert.Greater(t, got["follower-2"], got["follower-1"])
+	assert.Greater(t, got["follower-1"], 0)
+}
+
+func Test_sql_leastInFlightBalancer(t *testing.T) {
+	busyDB, _, _ := sqlmock.New()
+	idleDB, _, _ := sqlmock.New()
+
+	conn, err := busyDB.Conn(context.Background())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	nodes := []*node{
+		initNode(roleFollower, ConnConfig{Host: "busy"}, initCommand(sqlx.NewDb(busyDB, "postgres"), nil, &observer{}, nil, nil)),
+		initNode(roleFollower, ConnConfig{Host: "idle"}, initCommand(sqlx.NewDb(idleDB, "postgres"), nil, &observer{}, nil, nil)),
+	}
+
+	b := initBalancer(LoadBalanceLeastInFlight)
+
+	assert.Equal(t, "idle", b.next(nodes).conf.Host)
+}
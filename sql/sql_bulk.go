@@ -0,0 +1,284 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+)
+
+// defaultMaxPlaceholders is used for drivers without a known bindvar limit.
+const defaultMaxPlaceholders = 999
+
+// maxPlaceholders is the maximum number of bindvars in one statement per driver.
+var maxPlaceholders = map[string]int{
+	"postgres": 65535,
+	"mysql":    65535,
+	"sqlite":   32766,
+}
+
+// UpsertOptions configures the conflict handling of Upsert.
+type UpsertOptions struct {
+	// ConflictColumns is the conflict target, it is required on postgres and sqlite and ignored on mysql,
+	// where the conflict is detected on any unique key.
+	ConflictColumns []string
+	// UpdateColumns are updated with the inserted values on conflict. It defaults to every inserted column
+	// that is not a conflict column, an empty list after that leaves conflicting rows unchanged.
+	UpdateColumns []string
+}
+
+// bulkRows is a slice of structs flattened into insert columns and values.
+type bulkRows struct {
+	columns []string
+	values  [][]interface{}
+}
+
+// BulkInsert inserts every row of rows, a slice of structs or struct pointers, into table. Only fields with
+// a `db` tag are inserted. Rows are split into as few statements as the driver bindvar limit allows and
+// inserted in one transaction, the one in the context if any. It returns the total rows affected.
+func (s *sqlDB) BulkInsert(ctx context.Context, name string, table string, rows interface{}) (int64, error) {
+	return s.bulk(ctx, name, func(tx CommandTx) (int64, error) {
+		return tx.BulkInsert(name, table, rows)
+	})
+}
+
+// Upsert works like BulkInsert, but updates the conflicting rows instead. On mysql an updated row counts as
+// two rows affected.
+func (s *sqlDB) Upsert(ctx context.Context, name string, table string, rows interface{}, opt UpsertOptions) (int64, error) {
+	return s.bulk(ctx, name, func(tx CommandTx) (int64, error) {
+		return tx.Upsert(name, table, rows, opt)
+	})
+}
+
+func (s *sqlDB) bulk(ctx context.Context, name string, fn func(tx CommandTx) (int64, error)) (int64, error) {
+	if tx, ok := s.getTx(ctx); ok {
+		return fn(tx)
+	}
+
+	var affected int64
+	err := s.Transaction(ctx, name, TxOptions{}, func(ctx context.Context) error {
+		tx, _ := s.getTx(ctx)
+
+		n, err := fn(tx)
+		affected = n
+		return err
+	})
+
+	return affected, err
+}
+
+func (x *commandTx) BulkInsert(name string, table string, rows interface{}) (int64, error) {
+	return x.bulkExec(name, table, rows, nil)
+}
+
+func (x *commandTx) Upsert(name string, table string, rows interface{}, opt UpsertOptions) (int64, error) {
+	return x.bulkExec(name, table, rows, &opt)
+}
+
+func (x *commandTx) bulkExec(name string, table string, rows interface{}, upsert *UpsertOptions) (int64, error) {
+	driver := x.tx.DriverName()
+
+	b, err := flattenRows(rows)
+	if err != nil {
+		return 0, errors.NewWithCode(codes.CodeSQLBuilder, "%s: %s", name, err.Error())
+	}
+	if len(b.values) == 0 {
+		return 0, nil
+	}
+
+	suffix := ""
+	if upsert != nil {
+		suffix, err = upsertClause(driver, b.columns, *upsert)
+		if err != nil {
+			return 0, errors.NewWithCode(codes.CodeSQLBuilder, "%s: %s", name, err.Error())
+		}
+	}
+
+	var affected int64
+	for _, chunk := range b.chunks(driver) {
+		query, args := insertQuery(table, b.columns, chunk)
+		res, err := x.Exec(name, x.tx.Rebind(query+suffix), args...)
+		if err != nil {
+			return affected, err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return affected, wrapError(err, codes.CodeSQLTxExec, name)
+		}
+		affected += n
+	}
+
+	return affected, nil
+}
+
+// chunks splits the values so each statement stays below the driver bindvar limit.
+func (b bulkRows) chunks(driver string) [][][]interface{} {
+	limit, ok := maxPlaceholders[driver]
+	if !ok {
+		limit = defaultMaxPlaceholders
+	}
+
+	size := limit / len(b.columns)
+	if size < 1 {
+		size = 1
+	}
+
+	chunks := make([][][]interface{}, 0, len(b.values)/size+1)
+	for start := 0; start < len(b.values); start += size {
+		end := start + size
+		if end > len(b.values) {
+			end = len(b.values)
+		}
+		chunks = append(chunks, b.values[start:end])
+	}
+
+	return chunks
+}
+
+func insertQuery(table string, columns []string, rows [][]interface{}) (string, []interface{}) {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	var q strings.Builder
+	fmt.Fprintf(&q, "INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for i, values := range rows {
+		if i > 0 {
+			q.WriteString(", ")
+		}
+		q.WriteString(row)
+		args = append(args, values...)
+	}
+
+	return q.String(), args
+}
+
+func upsertClause(driver string, columns []string, opt UpsertOptions) (string, error) {
+	update := opt.UpdateColumns
+	if update == nil {
+		for _, c := range columns {
+			if !contains(opt.ConflictColumns, c) {
+				update = append(update, c)
+			}
+		}
+	}
+
+	set := make([]string, 0, len(update))
+	switch driver {
+	case "mysql":
+		for _, c := range update {
+			set = append(set, fmt.Sprintf("%s = VALUES(%s)", c, c))
+		}
+		if len(set) == 0 {
+			// a no-op update keeps the conflicting row, unlike INSERT IGNORE which also ignores other errors
+			set = append(set, fmt.Sprintf("%s = %s", columns[0], columns[0]))
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", "), nil
+	default:
+		if len(opt.ConflictColumns) == 0 {
+			return "", fmt.Errorf("upsert on %s requires conflict columns", driver)
+		}
+
+		for _, c := range update {
+			set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", c, c))
+		}
+		target := "(" + strings.Join(opt.ConflictColumns, ", ") + ")"
+		if len(set) == 0 {
+			return " ON CONFLICT " + target + " DO NOTHING", nil
+		}
+		return " ON CONFLICT " + target + " DO UPDATE SET " + strings.Join(set, ", "), nil
+	}
+}
+
+// flattenRows reads the `db` tagged fields of a slice of structs, including fields of embedded structs.
+func flattenRows(rows interface{}) (bulkRows, error) {
+	v := reflect.Indirect(reflect.ValueOf(rows))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return bulkRows{}, fmt.Errorf("rows must be a slice of structs, got %T", rows)
+	}
+
+	elem := v.Type().Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return bulkRows{}, fmt.Errorf("rows must be a slice of structs, got %T", rows)
+	}
+
+	var (
+		b       bulkRows
+		indexes [][]int
+	)
+	walkColumns(elem, nil, func(column string, index []int) {
+		b.columns = append(b.columns, column)
+		indexes = append(indexes, index)
+	})
+	if len(b.columns) == 0 {
+		return bulkRows{}, fmt.Errorf("%s has no fields with a db tag", elem)
+	}
+
+	b.values = make([][]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		for row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				return bulkRows{}, fmt.Errorf("row %d is nil", i)
+			}
+			row = row.Elem()
+		}
+
+		values := make([]interface{}, len(indexes))
+		for j, index := range indexes {
+			field, err := row.FieldByIndexErr(index)
+			if err != nil {
+				// a nil embedded struct pointer is inserted as NULL
+				values[j] = nil
+				continue
+			}
+			values[j] = field.Interface()
+		}
+		b.values = append(b.values, values)
+	}
+
+	return b, nil
+}
+
+func walkColumns(t reflect.Type, parent []int, fn func(column string, index []int)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+
+		tag, hasTag := f.Tag.Lookup("db")
+		column := strings.Split(tag, ",")[0]
+		if column == "-" {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && !hasTag && ft.Kind() == reflect.Struct {
+			walkColumns(ft, index, fn)
+			continue
+		}
+
+		if !f.IsExported() || column == "" {
+			continue
+		}
+		fn(column, index)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
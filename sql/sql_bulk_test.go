@@ -0,0 +1,108 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type bulkBase struct {
+	ID int64 `db:"id"`
+}
+
+type bulkUser struct {
+	bulkBase
+	Name     string `db:"name"`
+	Email    string `db:"email"`
+	Internal string `db:"-"`
+	ignored  string
+}
+
+func Test_sql_upsertClause(t *testing.T) {
+	tests := []struct {
+		name    string
+		driver  string
+		opt     UpsertOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "postgres updates every non conflict column",
+			driver: "postgres",
+			opt:    UpsertOptions{ConflictColumns: []string{"id"}},
+			want:   " ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, email = EXCLUDED.email",
+		},
+		{
+			name:   "postgres without update columns",
+			driver: "postgres",
+			opt:    UpsertOptions{ConflictColumns: []string{"id"}, UpdateColumns: []string{}},
+			want:   " ON CONFLICT (id) DO NOTHING",
+		},
+		{
+			name:    "postgres without conflict columns",
+			driver:  "postgres",
+			wantErr: true,
+		},
+		{
+			name:   "mysql",
+			driver: "mysql",
+			opt:    UpsertOptions{UpdateColumns: []string{"name"}},
+			want:   " ON DUPLICATE KEY UPDATE name = VALUES(name)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := upsertClause(tt.driver, []string{"id", "name", "email"}, tt.opt)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("upsertClause() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_sql_bulkRows_chunks(t *testing.T) {
+	b := bulkRows{columns: make([]string, 400), values: make([][]interface{}, 6)}
+
+	got := []int{}
+	for _, c := range b.chunks("unknown") {
+		got = append(got, len(c))
+	}
+
+	assert.Equal(t, []int{2, 2, 2}, got)
+}
+
+func Test_sql_Upsert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO users \(id, name, email\) VALUES \(\$1, \$2, \$3\), \(\$4, \$5, \$6\) ON CONFLICT \(id\) DO UPDATE SET name = EXCLUDED.name, email = EXCLUDED.email`).
+		WithArgs(1, "john", "john@mail.com", 2, "jane", "jane@mail.com").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	db := Init(Config{
+		Driver: "postgres",
+		Leader: ConnConfig{MockDB: mockDB},
+	}, logger)
+
+	users := []*bulkUser{
+		{bulkBase: bulkBase{ID: 1}, Name: "john", Email: "john@mail.com", Internal: "x"},
+		{bulkBase: bulkBase{ID: 2}, Name: "jane", Email: "jane@mail.com", ignored: "x"},
+	}
+
+	got, err := db.Upsert(context.Background(), "upsert users", "users", users, UpsertOptions{ConflictColumns: []string{"id"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
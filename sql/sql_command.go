@@ -3,23 +3,23 @@ package sql
 import (
 	"context"
 	"database/sql"
-	"fmt"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/reyhanmichiels/go-pkg/v2/codes"
-	"github.com/reyhanmichiels/go-pkg/v2/errors"
 	"github.com/reyhanmichiels/go-pkg/v2/log"
 )
 
 type Command interface {
 	Close() error
 	Ping(ctx context.Context) error
+	Stats() sql.DBStats
 
 	Rebind(query string) string
 
-	QueryRow(ctx context.Context, name string, query string, args ...interface{}) (*sqlx.Row, error)
-	Query(ctx context.Context, name string, query string, args ...interface{}) (*sqlx.Rows, error)
+	QueryRow(ctx context.Context, name string, query string, args ...interface{}) (*Row, error)
+	Query(ctx context.Context, name string, query string, args ...interface{}) (*Rows, error)
 	Get(ctx context.Context, name string, query string, dest interface{}, args ...interface{}) error
+	Select(ctx context.Context, name string, query string, dest interface{}, args ...interface{}) error
 
 	Prepare(ctx context.Context, name string, query string) (CommandStmt, error)
 	PrepareNamed(ctx context.Context, name string, query string) (NamedCommandStmt, error)
@@ -30,28 +30,40 @@ type Command interface {
 }
 
 type command struct {
-	db       *sqlx.DB
-	log      log.Interface
-	logQuery bool
+	db      *sqlx.DB
+	log     log.Interface
+	obs     *observer
+	timeout *queryTimeout
+	stmts   *stmtCache
 }
 
 type TxOptions struct {
 	Isolation sql.IsolationLevel
 	ReadOnly  bool
+	Retry     RetryPolicy
 }
 
-func initCommand(db *sqlx.DB, log log.Interface, logQuery bool) Command {
+func initCommand(db *sqlx.DB, log log.Interface, obs *observer, timeout *queryTimeout, stmts *stmtCache) Command {
 	c := &command{
-		db:       db,
-		log:      log,
-		logQuery: logQuery,
+		db:      db,
+		log:     log,
+		obs:     obs,
+		timeout: timeout,
+		stmts:   stmts,
 	}
 
 	return c
 }
 
 func (c *command) Close() error {
-	return c.db.Close()
+	if err := c.stmts.close(); err != nil {
+		c.log.Error(context.Background(), wrapError(err, codes.CodeSQL, "close statements"))
+	}
+	return wrapError(c.db.Close(), codes.CodeSQL, "close")
+}
+
+func (c *command) Stats() sql.DBStats {
+	return c.db.Stats()
 }
 
 func (c *command) Rebind(query string) string {
@@ -59,58 +71,91 @@ func (c *command) Rebind(query string) string {
 }
 
 func (c *command) Ping(ctx context.Context) error {
-	return c.db.PingContext(ctx)
+	return wrapError(c.db.PingContext(ctx), codes.CodeSQL, "ping")
 }
 
-func (c *command) QueryRow(ctx context.Context, name string, query string, args ...interface{}) (*sqlx.Row, error) {
-	if c.logQuery {
-		c.log.Info(ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query, args...)))
-	}
-	row := c.db.QueryRowxContext(ctx, query, args...)
-	return row, row.Err()
+func (c *command) QueryRow(ctx context.Context, name string, query string, args ...interface{}) (*Row, error) {
+	stmtCtx, cancel := c.timeout.withTimeout(ctx, name) // released once the row is scanned
+	done := c.obs.observe(ctx, name, query, args...)
+	row := c.db.QueryRowxContext(stmtCtx, query, args...)
+	done(-1, row.Err())
+	return initRow(row, cancel), wrapStmtError(stmtCtx, row.Err(), codes.CodeSQLRead, name)
 }
 
-func (c *command) Query(ctx context.Context, name string, query string, args ...interface{}) (*sqlx.Rows, error) {
-	if c.logQuery {
-		c.log.Info(ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query, args...)))
-	}
-	return c.db.QueryxContext(ctx, query, args...)
+func (c *command) Query(ctx context.Context, name string, query string, args ...interface{}) (*Rows, error) {
+	stmtCtx, cancel := c.timeout.withTimeout(ctx, name) // released once the rows are closed
+	done := c.obs.observe(ctx, name, query, args...)
+	rows, err := c.db.QueryxContext(stmtCtx, query, args...)
+	done(-1, err)
+	return initRows(rows, cancel), wrapStmtError(stmtCtx, err, codes.CodeSQLRead, name)
 }
 
+// Prepare prepares a statement, with the statement cache enabled it returns the cached statement of name and query.
 func (c *command) Prepare(ctx context.Context, name string, query string) (CommandStmt, error) {
-	if c.logQuery {
-		c.log.Info(ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query)))
+	key := stmtCacheKey("stmt", name, query)
+	if entry, ok := c.stmts.get(key); ok {
+		return initCachedStmt(ctx, c.stmts, entry), nil
 	}
-	stmt, err := c.db.PreparexContext(ctx, query)
+
+	stmtCtx, cancel := c.timeout.withTimeout(ctx, name)
+	defer cancel()
+
+	done := c.obs.observe(ctx, name, query)
+	stmt, err := c.db.PreparexContext(stmtCtx, query)
+	done(-1, err)
 	if err != nil {
-		return nil, err
+		return nil, wrapStmtError(stmtCtx, err, codes.CodeSQLPrepareStmt, name)
 	}
-	return initStmt(ctx, stmt), err
+
+	if c.stmts != nil {
+		entry := c.stmts.add(&stmtCacheEntry{key: key, stmt: stmt})
+		return initCachedStmt(ctx, c.stmts, entry), nil
+	}
+	return initStmt(ctx, stmt), nil
 }
 
+// PrepareNamed prepares a named statement, with the statement cache enabled it returns the cached statement of name and query.
 func (c *command) PrepareNamed(ctx context.Context, name string, query string) (NamedCommandStmt, error) {
-	if c.logQuery {
-		c.log.Info(ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query)))
+	key := stmtCacheKey("named", name, query)
+	if entry, ok := c.stmts.get(key); ok {
+		return initCachedNamedStmt(ctx, name, c.stmts, entry), nil
 	}
-	stmt, err := c.db.PrepareNamedContext(ctx, query)
+
+	stmtCtx, cancel := c.timeout.withTimeout(ctx, name)
+	defer cancel()
+
+	done := c.obs.observeNamed(ctx, name, query, nil)
+	stmt, err := c.db.PrepareNamedContext(stmtCtx, query)
+	done(-1, err)
 	if err != nil {
-		return nil, err
+		return nil, wrapStmtError(stmtCtx, err, codes.CodeSQLPrepareStmt, name)
 	}
-	return initNamedStmt(ctx, stmt), err
+
+	if c.stmts != nil {
+		entry := c.stmts.add(&stmtCacheEntry{key: key, named: stmt})
+		return initCachedNamedStmt(ctx, name, c.stmts, entry), nil
+	}
+	return initNamedStmt(ctx, name, stmt), nil
 }
 
 func (c *command) NamedExec(ctx context.Context, name string, query string, args interface{}) (sql.Result, error) {
-	if c.logQuery {
-		c.log.Info(ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query)))
-	}
-	return c.db.NamedExecContext(ctx, query, args)
+	stmtCtx, cancel := c.timeout.withTimeout(ctx, name)
+	defer cancel()
+
+	done := c.obs.observeNamed(ctx, name, query, args)
+	res, err := c.db.NamedExecContext(stmtCtx, query, args)
+	done(rowsAffected(res, err), err)
+	return res, wrapStmtError(stmtCtx, err, codes.CodeSQL, name)
 }
 
 func (c *command) Exec(ctx context.Context, name string, query string, args ...interface{}) (sql.Result, error) {
-	if c.logQuery {
-		c.log.Info(ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query, args...)))
-	}
-	return c.db.ExecContext(ctx, query, args...)
+	stmtCtx, cancel := c.timeout.withTimeout(ctx, name)
+	defer cancel()
+
+	done := c.obs.observe(ctx, name, query, args...)
+	res, err := c.db.ExecContext(stmtCtx, query, args...)
+	done(rowsAffected(res, err), err)
+	return res, wrapStmtError(stmtCtx, err, codes.CodeSQL, name)
 }
 
 func (c *command) BeginTx(ctx context.Context, name string, opt TxOptions) (CommandTx, error) {
@@ -120,33 +165,46 @@ func (c *command) BeginTx(ctx context.Context, name string, opt TxOptions) (Comm
 	}
 	tx, err := c.db.BeginTxx(ctx, opts)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, codes.CodeSQLTxBegin, name)
 	}
-	return initTx(ctx, name, tx, c.log, c.logQuery), nil
+	return initTx(ctx, name, tx, c.log, c.obs, c.timeout), nil
 }
 
+// ExecuteInTransaction runs fn in a transaction, the whole transaction is run again
+// when it fails with an error that is retryable according to opt.Retry.
 func (c *command) ExecuteInTransaction(ctx context.Context, name string, opt TxOptions, fn func(tx CommandTx) error) error {
-	tx, err := c.BeginTx(ctx, name, opt)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	err = fn(tx)
-	if err != nil {
-		return err
-	}
+	return retry(ctx, c.db.DriverName(), opt.Retry, func() error {
+		tx, err := c.BeginTx(ctx, name, opt)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
 
-	if err := tx.Commit(); err != nil {
-		return errors.NewWithCode(codes.CodeInternalServerError, err.Error())
-	}
+		err = fn(tx)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		return tx.Commit()
+	})
 }
 
 func (c *command) Get(ctx context.Context, name string, query string, dest interface{}, args ...interface{}) error {
-	if c.logQuery {
-		c.log.Info(ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query, args...)))
-	}
-	return c.db.GetContext(ctx, dest, query, args...)
+	stmtCtx, cancel := c.timeout.withTimeout(ctx, name)
+	defer cancel()
+
+	done := c.obs.observe(ctx, name, query, args...)
+	err := c.db.GetContext(stmtCtx, dest, query, args...)
+	done(-1, err)
+	return wrapStmtError(stmtCtx, err, codes.CodeSQLRead, name)
+}
+
+func (c *command) Select(ctx context.Context, name string, query string, dest interface{}, args ...interface{}) error {
+	stmtCtx, cancel := c.timeout.withTimeout(ctx, name)
+	defer cancel()
+
+	done := c.obs.observe(ctx, name, query, args...)
+	err := c.db.SelectContext(stmtCtx, dest, query, args...)
+	done(-1, err)
+	return wrapStmtError(stmtCtx, err, codes.CodeSQLRead, name)
 }
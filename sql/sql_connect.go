@@ -0,0 +1,143 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/reyhanmichiels/go-pkg/v2/operator"
+)
+
+const (
+	defaultConnectInitialBackoff = 500 * time.Millisecond
+	defaultConnectMaxBackoff     = 10 * time.Second
+	defaultConnectPingTimeout    = 5 * time.Second
+
+	failedConnectDBMessage = "cannot connect to db %s %s: %s on port %d, with error: %s"
+	lazyConnectLogMessage  = "SQL: [%s] db=%s @%s:%v is not reachable yet, reconnecting in the background, with error: %s"
+	reconnectedLogMessage  = "SQL: [%s] db=%s @%s:%v connected"
+)
+
+// ConnectConfig controls how Init connects. By default a node that cannot be pinged fails right away.
+type ConnectConfig struct {
+	// Timeout is how long a failed ping is retried before giving up, zero tries once.
+	Timeout        time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Lazy does not fail when a node is still unreachable after Timeout. The node is marked unhealthy
+	// and pinged in the background until it is reachable, while its pool opens connections on first use.
+	Lazy bool
+}
+
+// connect opens the pool of a node, it does not connect yet.
+func (s *sqlDB) connect(role string, conf ConnConfig) (*sqlx.DB, error) {
+	if conf.MockDB != nil {
+		return sqlx.NewDb(conf.MockDB, s.cfg.Driver), nil
+	}
+
+	uri, err := s.getURI(conf)
+	if err != nil {
+		return nil, errors.NewWithCode(codes.CodeSQLInit, "cannot get URI for db %s %s: %s on port %d, with error: %s", conf.DB, role, conf.Host, conf.Port, err)
+	}
+
+	sqlxDB, err := sqlx.Open(s.cfg.Driver, uri)
+	if err != nil {
+		return nil, errors.NewWithCode(codes.CodeSQLInit, failedConnectDBMessage, conf.DB, role, conf.Host, conf.Port, err)
+	}
+
+	if s.cfg.Driver == sqliteDriver && isSQLiteInMemory(conf) {
+		conf.Options = ConnOptions{MaxOpen: 1, MaxIdle: 1}
+	}
+
+	sqlxDB.SetMaxOpenConns(conf.Options.MaxOpen)
+	sqlxDB.SetMaxIdleConns(conf.Options.MaxIdle)
+	sqlxDB.SetConnMaxLifetime(conf.Options.MaxLifeTime)
+	sqlxDB.SetConnMaxIdleTime(conf.Options.MaxIdleTime)
+
+	return sqlxDB, nil
+}
+
+// pingNodes waits until every node can be pinged, sharing one deadline between the nodes.
+func (s *sqlDB) pingNodes() error {
+	deadline := time.Now().Add(s.cfg.Connect.Timeout)
+
+	nodes := append([]*node{s.leader}, s.followers...)
+	for _, n := range nodes {
+		if n.conf.MockDB != nil {
+			continue
+		}
+
+		err := s.ping(n, deadline)
+		if err == nil {
+			continue
+		}
+
+		if !s.cfg.Connect.Lazy {
+			return errors.WrapWithCode(err, codes.CodeSQLInit, failedConnectDBMessage, n.conf.DB, n.role, n.conf.Host, n.conf.Port, err.Error())
+		}
+
+		n.setHealth(err)
+		s.log.Warn(context.Background(), fmt.Sprintf(lazyConnectLogMessage, n.role, n.conf.DB, n.conf.Host, n.conf.Port, err))
+		s.reconnect(n)
+	}
+
+	return nil
+}
+
+// ping retries a failed ping with backoff until it succeeds or the deadline has passed.
+func (s *sqlDB) ping(n *node, deadline time.Time) error {
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultConnectPingTimeout)
+		err := n.command.Ping(ctx)
+		cancel()
+
+		wait := s.connectBackoff(attempt)
+		if err == nil || time.Now().Add(wait).After(deadline) {
+			return err
+		}
+
+		select {
+		case <-s.stop:
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// reconnect pings n in the background until it is reachable or Stop is called.
+func (s *sqlDB) reconnect(n *node) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for attempt := 1; ; attempt++ {
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(s.connectBackoff(attempt)):
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), defaultConnectPingTimeout)
+			err := n.command.Ping(ctx)
+			cancel()
+
+			if err == nil {
+				n.setHealth(nil)
+				s.log.Info(context.Background(), fmt.Sprintf(reconnectedLogMessage, n.role, n.conf.DB, n.conf.Host, n.conf.Port))
+				return
+			}
+		}
+	}()
+}
+
+func (s *sqlDB) connectBackoff(attempt int) time.Duration {
+	policy := RetryPolicy{
+		InitialBackoff: operator.Ternary(s.cfg.Connect.InitialBackoff <= 0, defaultConnectInitialBackoff, s.cfg.Connect.InitialBackoff),
+		MaxBackoff:     operator.Ternary(s.cfg.Connect.MaxBackoff <= 0, defaultConnectMaxBackoff, s.cfg.Connect.MaxBackoff),
+	}
+
+	return policy.backoff(attempt)
+}
@@ -0,0 +1,62 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_sql_InitWithError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+
+	// nothing listens on port 1, so every ping is refused
+	unreachable := ConnConfig{Host: "127.0.0.1", Port: 1, DB: "app", User: "app"}
+
+	tests := []struct {
+		name        string
+		connect     ConnectConfig
+		wantErr     bool
+		wantHealthy bool
+	}{
+		{
+			name:    "fails after retrying until the timeout",
+			connect: ConnectConfig{Timeout: 50 * time.Millisecond, InitialBackoff: 10 * time.Millisecond},
+			wantErr: true,
+		},
+		{
+			name:        "lazy starts with an unhealthy leader",
+			connect:     ConnectConfig{Lazy: true, InitialBackoff: time.Hour},
+			wantHealthy: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := InitWithError(Config{
+				Driver:  "postgres",
+				Leader:  unreachable,
+				Connect: tt.connect,
+			}, logger)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("InitWithError() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				assert.Equal(t, codes.CodeSQLInit, errors.GetCode(err))
+				return
+			}
+			defer db.Stop()
+
+			assert.Equal(t, tt.wantHealthy, db.Health().Healthy)
+		})
+	}
+}
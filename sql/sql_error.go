@@ -0,0 +1,97 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+)
+
+// postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+	pqNotNullViolation    = "23502"
+	pqQueryCanceled       = "57014"
+)
+
+// mysql error numbers, see https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	mysqlDuplicateEntry         = 1062
+	mysqlRowIsReferenced        = 1451
+	mysqlNoReferencedRow        = 1452
+	mysqlColumnCannotBeNull     = 1048
+	mysqlNoDefaultForField      = 1364
+	mysqlLockWaitTimeout        = 1205
+	mysqlMaxExecutionTimeExceed = 3024
+)
+
+// wrapError wraps a driver error with the codes.Code matching its cause, using fallback when the
+// cause is unknown. The driver error is kept as the cause, so errors.Is(err, ErrNotFound) still matches.
+func wrapError(err error, fallback codes.Code, name string) error {
+	if err == nil || errors.GetCode(err) != codes.NoCode {
+		return err
+	}
+
+	return wrapWithCaller(err, classifyError(err, fallback), name)
+}
+
+// wrapWithCaller wraps err with code, recording the function that called wrapError or wrapStmtError
+// as the caller instead of the helpers.
+func wrapWithCaller(err error, code codes.Code, name string) error {
+	return errors.WrapWithCodeSkip(2, err, code, "%s: %s", name, err.Error())
+}
+
+func classifyError(err error, fallback codes.Code) codes.Code {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return codes.CodeSQLRecordDoesNotExist
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.CodeContextDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.CodeContextCanceled
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			return codes.CodeSQLUniqueConstraint
+		case pqForeignKeyViolation:
+			return codes.CodeSQLForeignKeyViolation
+		case pqNotNullViolation:
+			return codes.CodeSQLNotNullViolation
+		case pqDeadlockDetected:
+			return codes.CodeSQLDeadlock
+		case pqSerializationFailure:
+			return codes.CodeSQLConflict
+		case pqQueryCanceled:
+			return codes.CodeContextDeadlineExceeded
+		}
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlDuplicateEntry:
+			return codes.CodeSQLUniqueConstraint
+		case mysqlRowIsReferenced, mysqlNoReferencedRow:
+			return codes.CodeSQLForeignKeyViolation
+		case mysqlColumnCannotBeNull, mysqlNoDefaultForField:
+			return codes.CodeSQLNotNullViolation
+		case mysqlDeadlock:
+			return codes.CodeSQLDeadlock
+		case mysqlLockWaitTimeout, mysqlMaxExecutionTimeExceed:
+			return codes.CodeContextDeadlineExceeded
+		}
+	}
+
+	if code, ok := classifySQLiteError(err); ok {
+		return code
+	}
+
+	return fallback
+}
@@ -0,0 +1,84 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sql_wrapError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		fallback codes.Code
+		want     codes.Code
+	}{
+		{
+			name:     "no rows",
+			err:      ErrNotFound,
+			fallback: codes.CodeSQLRead,
+			want:     codes.CodeSQLRecordDoesNotExist,
+		},
+		{
+			name:     "context deadline",
+			err:      fmt.Errorf("query: %w", context.DeadlineExceeded),
+			fallback: codes.CodeSQLRead,
+			want:     codes.CodeContextDeadlineExceeded,
+		},
+		{
+			name:     "postgres unique violation",
+			err:      &pq.Error{Code: "23505"},
+			fallback: codes.CodeSQL,
+			want:     codes.CodeSQLUniqueConstraint,
+		},
+		{
+			name:     "postgres foreign key violation",
+			err:      &pq.Error{Code: "23503"},
+			fallback: codes.CodeSQL,
+			want:     codes.CodeSQLForeignKeyViolation,
+		},
+		{
+			name:     "mysql not null violation",
+			err:      &mysql.MySQLError{Number: 1048},
+			fallback: codes.CodeSQL,
+			want:     codes.CodeSQLNotNullViolation,
+		},
+		{
+			name:     "mysql deadlock",
+			err:      &mysql.MySQLError{Number: 1213},
+			fallback: codes.CodeSQLTxExec,
+			want:     codes.CodeSQLDeadlock,
+		},
+		{
+			name:     "sqlite unique violation",
+			err:      fmt.Errorf("constraint failed: UNIQUE constraint failed: users.email (2067)"),
+			fallback: codes.CodeSQL,
+			want:     codes.CodeSQLUniqueConstraint,
+		},
+		{
+			name:     "unknown error",
+			err:      fmt.Errorf("connection refused"),
+			fallback: codes.CodeSQLTxBegin,
+			want:     codes.CodeSQLTxBegin,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wrapError(tt.err, tt.fallback, "test query")
+			assert.Equal(t, tt.want, errors.GetCode(err))
+			assert.True(t, errors.Is(err, tt.err))
+
+			file, _, _, _ := errors.GetCaller(err)
+			assert.True(t, strings.HasSuffix(file, "/sql_error_test.go"), file)
+		})
+	}
+
+	assert.Nil(t, wrapError(nil, codes.CodeSQL, "test query"))
+}
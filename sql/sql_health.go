@@ -0,0 +1,160 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/reyhanmichiels/go-pkg/v2/operator"
+)
+
+const (
+	roleLeader   = "LEADER"
+	roleFollower = "FOLLOWER"
+
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+)
+
+type HealthCheckConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// HealthStatus is the last known state of every node, intended for readiness probes.
+// Healthy follows the leader, since writes cannot be served without it.
+type HealthStatus struct {
+	Healthy   bool
+	Leader    NodeHealth
+	Followers []NodeHealth
+}
+
+type NodeHealth struct {
+	Host      string
+	Port      int
+	DB        string
+	Healthy   bool
+	Error     string
+	CheckedAt time.Time
+}
+
+type node struct {
+	role      string
+	conf      ConnConfig
+	command   Command
+	mu        *sync.RWMutex
+	healthy   bool
+	err       error
+	checkedAt time.Time
+}
+
+func initNode(role string, conf ConnConfig, command Command) *node {
+	return &node{
+		role:      role,
+		conf:      conf,
+		command:   command,
+		mu:        &sync.RWMutex{},
+		healthy:   true,
+		checkedAt: time.Now(),
+	}
+}
+
+func (n *node) isHealthy() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.healthy
+}
+
+// setHealth stores the ping result and reports whether the node changed state.
+func (n *node) setHealth(err error) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	changed := n.healthy != (err == nil)
+	n.healthy = err == nil
+	n.err = err
+	n.checkedAt = time.Now()
+
+	return changed
+}
+
+func (n *node) health() NodeHealth {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	h := NodeHealth{
+		Host:      n.conf.Host,
+		Port:      n.conf.Port,
+		DB:        n.conf.DB,
+		Healthy:   n.healthy,
+		CheckedAt: n.checkedAt,
+	}
+	if n.err != nil {
+		h.Error = n.err.Error()
+	}
+
+	return h
+}
+
+func (s *sqlDB) Health() HealthStatus {
+	status := HealthStatus{
+		Leader:    s.leader.health(),
+		Followers: make([]NodeHealth, 0, len(s.followers)),
+	}
+	status.Healthy = status.Leader.Healthy
+
+	for _, f := range s.followers {
+		status.Followers = append(status.Followers, f.health())
+	}
+
+	return status
+}
+
+// startHealthCheck pings every node periodically until Stop is called.
+func (s *sqlDB) startHealthCheck() {
+	if !s.cfg.HealthCheck.Enabled {
+		return
+	}
+
+	interval := operator.Ternary(s.cfg.HealthCheck.Interval <= 0, defaultHealthCheckInterval, s.cfg.HealthCheck.Interval)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.checkHealth()
+			}
+		}
+	}()
+}
+
+func (s *sqlDB) checkHealth() {
+	timeout := operator.Ternary(s.cfg.HealthCheck.Timeout <= 0, defaultHealthCheckTimeout, s.cfg.HealthCheck.Timeout)
+
+	nodes := append([]*node{s.leader}, s.followers...)
+	for _, n := range nodes {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := n.command.Ping(ctx)
+		cancel()
+
+		if !n.setHealth(err) {
+			continue
+		}
+
+		if err != nil {
+			s.log.Warn(context.Background(), fmt.Sprintf("SQL: [%s] db=%s @%s:%v marked unhealthy, with error: %s", n.role, n.conf.DB, n.conf.Host, n.conf.Port, err))
+		} else {
+			s.log.Info(context.Background(), fmt.Sprintf("SQL: [%s] db=%s @%s:%v recovered", n.role, n.conf.DB, n.conf.Host, n.conf.Port))
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_sql_checkHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+
+	leaderDB, leaderMock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	followerDB, followerMock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+
+	db := Init(Config{
+		Driver:   "postgres",
+		Leader:   ConnConfig{Host: "leader", Port: 5432, MockDB: leaderDB},
+		Follower: ConnConfig{Host: "follower", Port: 5432, MockDB: followerDB},
+	}, logger).(*sqlDB)
+
+	tests := []struct {
+		name         string
+		followerErr  error
+		wantFollower bool
+	}{
+		{
+			name:         "follower unhealthy falls back to leader",
+			followerErr:  errors.New("connection refused"),
+			wantFollower: false,
+		},
+		{
+			name:         "follower recovered",
+			followerErr:  nil,
+			wantFollower: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaderMock.ExpectPing()
+			followerMock.ExpectPing().WillReturnError(tt.followerErr)
+
+			db.checkHealth()
+
+			assert.Equal(t, tt.wantFollower, db.Follower() == db.followers[0].command)
+			assert.Equal(t, tt.wantFollower, db.Health().Followers[0].Healthy)
+			assert.True(t, db.Health().Healthy)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"iter"
+	"reflect"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// Queryer runs a read statement. It is implemented by Interface and Command, use TxQueryer for a CommandTx.
+type Queryer interface {
+	Query(ctx context.Context, name string, query string, args ...interface{}) (*Rows, error)
+}
+
+type txQueryer struct {
+	tx CommandTx
+}
+
+// TxQueryer adapts tx to Queryer. The context of the transaction is used instead of the given one.
+func TxQueryer(tx CommandTx) Queryer {
+	return &txQueryer{tx: tx}
+}
+
+func (q *txQueryer) Query(_ context.Context, name string, query string, args ...interface{}) (*Rows, error) {
+	return q.tx.Query(name, query, args...)
+}
+
+// Iterate runs the query and returns an iterator over its rows, to be used with range-over-func.
+// Rows are closed when the iteration ends, also when it stops early. An error is yielded once, after which the iteration stops.
+// T is either a struct scanned by its `db` tags or a single column type such as int64, string or a sql.Scanner.
+func Iterate[T any](ctx context.Context, q Queryer, name string, query string, args ...interface{}) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		rows, err := q.Query(ctx, name, query, args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			v, err := scanRow[T](rows)
+			if err != nil {
+				yield(zero, wrapError(err, codes.CodeSQLRowScan, name))
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(zero, wrapError(err, codes.CodeSQLRowScan, name))
+		}
+	}
+}
+
+// SelectAll runs the query and scans every row into a T, see Iterate.
+func SelectAll[T any](ctx context.Context, q Queryer, name string, query string, args ...interface{}) ([]T, error) {
+	result := []T{}
+	for v, err := range Iterate[T](ctx, q, name, query, args...) {
+		if err != nil {
+			return result, err
+		}
+		result = append(result, v)
+	}
+
+	return result, nil
+}
+
+// GetOne runs the query and scans the first row into a T, see Iterate.
+// It returns an error matching ErrNotFound when there is no row.
+func GetOne[T any](ctx context.Context, q Queryer, name string, query string, args ...interface{}) (T, error) {
+	for v, err := range Iterate[T](ctx, q, name, query, args...) {
+		return v, err
+	}
+
+	var zero T
+	return zero, wrapError(ErrNotFound, codes.CodeSQLRead, name)
+}
+
+func scanRow[T any](rows *Rows) (T, error) {
+	var dest T
+	if isScannable(reflect.TypeOf(&dest).Elem()) {
+		return dest, rows.Scan(&dest)
+	}
+	return dest, rows.StructScan(&dest)
+}
+
+// isScannable follows sqlx: a type is scanned as a single column unless it is a struct
+// with exported fields that does not implement sql.Scanner.
+func isScannable(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(scannerType) || t.Kind() != reflect.Struct {
+		return true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
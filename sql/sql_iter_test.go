@@ -0,0 +1,62 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type iterUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func Test_sql_SelectAll(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT id, name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "john").AddRow(2, "jane")).
+		RowsWillBeClosed()
+
+	c := initCommand(sqlx.NewDb(mockDB, "postgres"), nil, &observer{renderer: initQueryRenderer("postgres", LogOptions{})}, nil, nil)
+
+	got, err := SelectAll[iterUser](context.Background(), c, "list users", "SELECT id, name FROM users")
+	assert.NoError(t, err)
+	assert.Equal(t, []iterUser{{ID: 1, Name: "john"}, {ID: 2, Name: "jane"}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_sql_Iterate(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3)).
+		RowsWillBeClosed()
+
+	c := initCommand(sqlx.NewDb(mockDB, "postgres"), nil, &observer{renderer: initQueryRenderer("postgres", LogOptions{})}, nil, nil)
+
+	got := []int64{}
+	for id, err := range Iterate[int64](context.Background(), c, "list user ids", "SELECT id FROM users") {
+		assert.NoError(t, err)
+		got = append(got, id)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	assert.Equal(t, []int64{1, 2}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_sql_GetOne(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT id, name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+	c := initCommand(sqlx.NewDb(mockDB, "postgres"), nil, &observer{renderer: initQueryRenderer("postgres", LogOptions{})}, nil, nil)
+
+	_, err := GetOne[iterUser](context.Background(), c, "get user", "SELECT id, name FROM users WHERE id = $1", 1)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -1,21 +1,488 @@
 package sql
 
 import (
+	"database/sql/driver"
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
 )
 
 const (
 	queryLogMessage = "executing query: %s, with query string: %s"
+
+	redactedValue = "'[REDACTED]'"
+)
+
+// DefaultRedactedColumns is used when LogOptions.RedactedColumns is nil.
+var DefaultRedactedColumns = []string{"password", "secret", "token", "pin", "otp"}
+
+var (
+	insertColumnsRegex = regexp.MustCompile(`(?is)^\s*(?:insert|replace)\s+(?:into\s+)?\S+\s*\(([^)]*)\)\s*values\s*`)
 )
 
-// Replace query bindvars with args value
-func replaceBindVarsWithArgs(str string, args ...interface{}) string {
-	str = strings.Join(strings.Fields(str), " ")
-	for _, a := range args {
-		str = strings.Replace(str, "?", fmt.Sprintf("%v", a), 1)
+// LogOptions controls how statements are rendered in query logs.
+type LogOptions struct {
+	// RedactedColumns are column or named parameter names whose values are replaced in the logs.
+	// A column is redacted when one of its words, separated by `_` or camelCase, is one of them, case insensitive.
+	RedactedColumns []string
+	// MaxArgLength truncates longer values in the logs, zero keeps them whole.
+	MaxArgLength int
+}
+
+// queryRenderer renders a statement with its args for logging. It understands `?`, `$n` and `:name` bindvars,
+// quotes values for the driver, truncates long values and redacts values of sensitive columns.
+type queryRenderer struct {
+	driver       string
+	redacted     []string
+	maxArgLength int
+}
+
+func initQueryRenderer(driver string, opt LogOptions) *queryRenderer {
+	redacted := opt.RedactedColumns
+	if redacted == nil {
+		redacted = DefaultRedactedColumns
+	}
+
+	r := &queryRenderer{
+		driver:       driver,
+		maxArgLength: opt.MaxArgLength,
+	}
+	for _, c := range redacted {
+		r.redacted = append(r.redacted, strings.ToLower(c))
+	}
+
+	return r
+}
+
+// render replaces positional bindvars with args. Bindvars without a matching arg are left as is.
+// The statement is scanned once, the column of every bindvar is followed along the way. A bindvar whose
+// column cannot be told is redacted when the statement mentions a redacted column.
+func (r *queryRenderer) render(query string, args ...interface{}) string {
+	query = strings.Join(strings.Fields(query), " ")
+	insertColumns, valuesStart := r.insertColumns(query)
+	sensitive := r.mentionsRedacted(query)
+
+	var (
+		b strings.Builder
+		t columnTracker
+		v valuesTracker
+	)
+	column := func(i int) string {
+		if i >= valuesStart && v.depth > 0 {
+			return v.column(insertColumns)
+		}
+		return t.current()
+	}
+	bindvar := func(i int, arg interface{}) {
+		col := column(i)
+		if col == "" && sensitive {
+			b.WriteString(redactedValue)
+		} else {
+			b.WriteString(r.renderArg(col, arg))
+		}
+		t.bindvar()
+	}
+
+	next := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := quotedEnd(query, i)
+			b.WriteString(query[i:end])
+			if c == '\'' {
+				t.char(c)
+			} else {
+				t.word(query[i+1 : end-1])
+			}
+			i = end - 1
+			continue
+		case isWordStart(c):
+			end := i + 1
+			for end < len(query) && isWordChar(query[end]) {
+				end++
+			}
+			b.WriteString(query[i:end])
+			t.word(query[i:end])
+			i = end - 1
+			continue
+		case c == '?':
+			if next >= len(args) {
+				b.WriteByte(c)
+				continue
+			}
+			bindvar(i, args[next])
+			next++
+			continue
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			end := i + 1
+			for end < len(query) && isDigit(query[end]) {
+				end++
+			}
+			n, _ := strconv.Atoi(query[i+1 : end])
+			if n < 1 || n > len(args) {
+				b.WriteString(query[i:end])
+			} else {
+				bindvar(i, args[n-1])
+			}
+			i = end - 1
+			continue
+		}
+
+		b.WriteByte(c)
+		t.char(c)
+		if i >= valuesStart {
+			v.char(c)
+		}
+	}
+
+	return b.String()
+}
+
+// renderNamed replaces `:name` bindvars with the matching field or key of arg. It falls back to the
+// statement without values when arg cannot be bound, e.g. for batch inserts.
+func (r *queryRenderer) renderNamed(query string, arg interface{}) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if arg == nil {
+		return query
+	}
+
+	_, args, err := sqlx.Named(query, arg)
+	if err != nil {
+		return query
+	}
+
+	// names are scanned the same way sqlx compiles them, so they line up with args.
+	var (
+		b    strings.Builder
+		next int
+	)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if c != ':' {
+			b.WriteByte(c)
+			continue
+		}
+
+		if i+1 < len(query) && query[i+1] == ':' {
+			b.WriteString("::")
+			i++
+			continue
+		}
+
+		end := i + 1
+		for end < len(query) && isNameChar(query[end]) {
+			end++
+		}
+		if end == i+1 || next >= len(args) {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+
+		b.WriteString(r.renderArg(query[i+1:end], args[next]))
+		next++
+		i = end - 1
+	}
+
+	if next != len(args) {
+		return query
+	}
+
+	return b.String()
+}
+
+// insertColumns returns the column list of an INSERT statement and where its VALUES start.
+func (r *queryRenderer) insertColumns(query string) ([]string, int) {
+	match := insertColumnsRegex.FindStringSubmatchIndex(query)
+	if match == nil {
+		return nil, len(query)
+	}
+
+	columns := strings.Split(query[match[2]:match[3]], ",")
+	for i, c := range columns {
+		columns[i] = strings.Trim(strings.TrimSpace(c), "\"`")
+	}
+
+	return columns, match[1]
+}
+
+// valuesTracker follows the position inside the VALUES tuples of an INSERT, so literals and
+// function calls between bindvars keep them in line with the column list.
+type valuesTracker struct {
+	depth int // depth is 1 inside a tuple, more inside a function call in it
+	pos   int
+}
+
+func (v *valuesTracker) char(c byte) {
+	switch c {
+	case '(':
+		v.depth++
+		if v.depth == 1 {
+			v.pos = 0
+		}
+	case ')':
+		if v.depth > 0 {
+			v.depth--
+		}
+	case ',':
+		if v.depth == 1 {
+			v.pos++
+		}
+	}
+}
+
+func (v *valuesTracker) column(columns []string) string {
+	if v.pos >= len(columns) {
+		return ""
+	}
+	return columns[v.pos]
+}
+
+// columnTracker follows the column a bindvar is compared with while a statement is scanned,
+// e.g. `u.password = ?`, `email LIKE ?`, every value of `id IN (?, ?)` or `password = crypt(?, gen_salt('bf'))`.
+type columnTracker struct {
+	column   string
+	compared bool // compared is set when an operator follows column
+	in       bool // in is set after IN, until the value list opens
+	inList   bool
+	call     int    // call counts the open parentheses of a function call wrapping the compared value
+	fn       string // fn is a word right after an operator, it is a function when a parenthesis follows
+}
+
+func (t *columnTracker) word(w string) {
+	if t.call > 0 {
+		t.fn = w
+		return
+	}
+	if t.fn != "" {
+		t.reset(t.fn)
+	}
+
+	switch strings.ToLower(w) {
+	case "not":
+	case "like":
+		t.compared = t.column != ""
+	case "in":
+		t.in = t.column != ""
+	default:
+		if t.compared && !t.inList {
+			t.fn = w
+			return
+		}
+		t.reset(w)
+	}
+}
+
+func (t *columnTracker) char(c byte) {
+	if c == ' ' {
+		return
+	}
+
+	if t.fn != "" {
+		if c == '(' {
+			t.fn = ""
+			t.call++
+			return
+		}
+		if t.call == 0 {
+			t.reset(t.fn)
+		}
+		t.fn = ""
+	}
+
+	if t.call > 0 {
+		switch c {
+		case '(':
+			t.call++
+		case ')':
+			t.call--
+			t.compared = t.call > 0
+		}
+		return
+	}
+
+	switch {
+	case c == '=' || c == '<' || c == '>' || c == '!':
+		t.compared = t.column != ""
+	case c == '(' && t.in:
+		t.in, t.inList, t.compared = false, true, true
+	case c == ',' && t.inList:
+		t.compared = true
+	default:
+		t.compared, t.in, t.inList = false, false, false
+	}
+}
+
+func (t *columnTracker) reset(column string) {
+	*t = columnTracker{column: column}
+}
+
+// bindvar is called after a bindvar, the next one is compared again only within an IN list or a function call.
+func (t *columnTracker) bindvar() {
+	if t.call == 0 {
+		t.compared = false
+	}
+}
+
+func (t *columnTracker) current() string {
+	if !t.compared {
+		return ""
+	}
+	return t.column
+}
+
+// mentionsRedacted reports whether a word of query outside string literals is a redacted column.
+func (r *queryRenderer) mentionsRedacted(query string) bool {
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\'':
+			i = quotedEnd(query, i) - 1
+		case isWordStart(c):
+			end := i + 1
+			for end < len(query) && isWordChar(query[end]) {
+				end++
+			}
+			if r.isRedacted(query[i:end]) {
+				return true
+			}
+			i = end - 1
+		}
+	}
+	return false
+}
+
+// isRedacted matches whole words of column, separated by `_` or camelCase, e.g. `pin` redacts
+// `user_pin` and `pinCode` but not `shipping_address`.
+func (r *queryRenderer) isRedacted(column string) bool {
+	if column == "" {
+		return false
+	}
+
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = column[i+1:]
+	}
+	column = "_" + snakeCase(column) + "_"
+
+	for _, c := range r.redacted {
+		if strings.Contains(column, "_"+c+"_") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' {
+			if i > 0 && (s[i-1] >= 'a' && s[i-1] <= 'z' || isDigit(s[i-1])) {
+				b.WriteByte('_')
+			}
+			c += 'a' - 'A'
+		}
+		b.WriteByte(c)
 	}
-	return str
+	return b.String()
 }
 
-// TODO: create func for replace Named Execution
+func (r *queryRenderer) renderArg(column string, arg interface{}) string {
+	if r.isRedacted(column) {
+		return redactedValue
+	}
+
+	if valuer, ok := arg.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return fmt.Sprintf("%v", arg)
+		}
+		arg = v
+	}
+
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%v", v)
+	case time.Time:
+		return r.quote(v.Format("2006-01-02 15:04:05.999999Z07:00"))
+	case []byte:
+		if !utf8.Valid(v) {
+			return r.hex(v)
+		}
+		return r.quote(string(v))
+	case string:
+		return r.quote(v)
+	default:
+		return r.quote(fmt.Sprintf("%v", v))
+	}
+}
+
+func (r *queryRenderer) quote(s string) string {
+	if r.maxArgLength > 0 && utf8.RuneCountInString(s) > r.maxArgLength {
+		s = string([]rune(s)[:r.maxArgLength]) + "..."
+	}
+
+	if r.driver == "mysql" {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (r *queryRenderer) hex(b []byte) string {
+	truncated := ""
+	if r.maxArgLength > 0 && len(b) > r.maxArgLength {
+		b, truncated = b[:r.maxArgLength], "..."
+	}
+
+	if r.driver == "postgres" {
+		return `'\x` + hex.EncodeToString(b) + truncated + "'"
+	}
+
+	return "X'" + hex.EncodeToString(b) + truncated + "'"
+}
+
+// quotedEnd returns the index right after the quoted literal or identifier starting at i.
+func quotedEnd(query string, i int) int {
+	quote := query[i]
+	for j := i + 1; j < len(query); j++ {
+		if query[j] != quote {
+			continue
+		}
+		// a doubled quote is an escaped quote
+		if j+1 < len(query) && query[j+1] == quote {
+			j++
+			continue
+		}
+		return j + 1
+	}
+	return len(query)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isWordChar(c byte) bool {
+	return isWordStart(c) || isDigit(c)
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '.' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,132 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sql_queryRenderer_render(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+		opt    LogOptions
+		query  string
+		args   []interface{}
+		want   string
+	}{
+		{
+			name:   "question mark bindvars",
+			driver: "mysql",
+			query:  "SELECT * FROM users WHERE id = ? AND name = ? AND active = ?",
+			args:   []interface{}{1, "o'neil", true},
+			want:   "SELECT * FROM users WHERE id = 1 AND name = 'o''neil' AND active = TRUE",
+		},
+		{
+			name:   "dollar bindvars",
+			driver: "postgres",
+			query:  "SELECT * FROM users WHERE name = $2 AND id = $1 AND deleted_at IS $3",
+			args:   []interface{}{1, "john", nil},
+			want:   "SELECT * FROM users WHERE name = 'john' AND id = 1 AND deleted_at IS NULL",
+		},
+		{
+			name:   "bindvars in literals are kept",
+			driver: "mysql",
+			query:  "SELECT '?' FROM users WHERE id = ?",
+			args:   []interface{}{1},
+			want:   "SELECT '?' FROM users WHERE id = 1",
+		},
+		{
+			name:   "redact compared column",
+			driver: "postgres",
+			query:  "SELECT * FROM users u WHERE u.email = $1 AND u.password = $2",
+			args:   []interface{}{"john@mail.com", "secret"},
+			want:   "SELECT * FROM users u WHERE u.email = 'john@mail.com' AND u.password = '[REDACTED]'",
+		},
+		{
+			name:   "redact every value of an in list",
+			driver: "postgres",
+			query:  `SELECT * FROM users WHERE "token" IN ($1, $2) AND name NOT LIKE $3`,
+			args:   []interface{}{"a", "b", "john%"},
+			want:   `SELECT * FROM users WHERE "token" IN ('[REDACTED]', '[REDACTED]') AND name NOT LIKE 'john%'`,
+		},
+		{
+			name:   "keep values with an unknown column",
+			driver: "postgres",
+			query:  "SELECT * FROM users WHERE id = 'x' || $1",
+			args:   []interface{}{1},
+			want:   "SELECT * FROM users WHERE id = 'x' || 1",
+		},
+		{
+			name:   "redact values with an unknown column when a redacted column is mentioned",
+			driver: "postgres",
+			query:  "SELECT * FROM users WHERE token = 'x' || $1",
+			args:   []interface{}{"y"},
+			want:   "SELECT * FROM users WHERE token = 'x' || '[REDACTED]'",
+		},
+		{
+			name:   "redact through function calls",
+			driver: "postgres",
+			query:  "UPDATE users SET password = crypt($1, gen_salt('bf')), name = LOWER($2) WHERE id = $3",
+			args:   []interface{}{"hunter2", "John", 1},
+			want:   "UPDATE users SET password = crypt('[REDACTED]', gen_salt('bf')), name = LOWER('John') WHERE id = 1",
+		},
+		{
+			name:   "match redacted columns on whole words",
+			driver: "postgres",
+			query:  "UPDATE users SET shipping_address = $1, opinion = $2, userPin = $3 WHERE id = $4",
+			args:   []interface{}{"street", "good", "1234", 1},
+			want:   "UPDATE users SET shipping_address = 'street', opinion = 'good', userPin = '[REDACTED]' WHERE id = 1",
+		},
+		{
+			name:   "redact insert columns",
+			driver: "mysql",
+			query:  "INSERT INTO users (email, password_hash) VALUES (?, ?), (?, ?)",
+			args:   []interface{}{"a@mail.com", "x", "b@mail.com", "y"},
+			want:   "INSERT INTO users (email, password_hash) VALUES ('a@mail.com', '[REDACTED]'), ('b@mail.com', '[REDACTED]')",
+		},
+		{
+			name:   "redact insert columns by position in the tuple",
+			driver: "postgres",
+			query:  "INSERT INTO users (email, created_at, password) VALUES ($1, NOW(), $2)",
+			args:   []interface{}{"a@mail.com", "hunter2"},
+			want:   "INSERT INTO users (email, created_at, password) VALUES ('a@mail.com', NOW(), '[REDACTED]')",
+		},
+		{
+			name:   "truncate long values",
+			driver: "postgres",
+			opt:    LogOptions{MaxArgLength: 3},
+			query:  "SELECT * FROM users WHERE name = $1",
+			args:   []interface{}{"johnny"},
+			want:   "SELECT * FROM users WHERE name = 'joh...'",
+		},
+		{
+			name:   "escape backslash for mysql",
+			driver: "mysql",
+			query:  "SELECT * FROM files WHERE path = ?",
+			args:   []interface{}{`C:\tmp`},
+			want:   `SELECT * FROM files WHERE path = 'C:\\tmp'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := initQueryRenderer(tt.driver, tt.opt)
+			assert.Equal(t, tt.want, r.render(tt.query, tt.args...))
+		})
+	}
+}
+
+func Test_sql_queryRenderer_renderNamed(t *testing.T) {
+	type user struct {
+		Email    string `db:"email"`
+		Password string `db:"password"`
+	}
+
+	r := initQueryRenderer("postgres", LogOptions{})
+
+	got := r.renderNamed("UPDATE users SET email = :email, password = :password WHERE created_at > now()::date", user{Email: "john@mail.com", Password: "secret"})
+	assert.Equal(t, "UPDATE users SET email = 'john@mail.com', password = '[REDACTED]' WHERE created_at > now()::date", got)
+
+	got = r.renderNamed("UPDATE users SET email = :email", map[string]interface{}{})
+	assert.Equal(t, "UPDATE users SET email = :email", got)
+}
@@ -0,0 +1,101 @@
+package sql
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// QueryStats holds the metrics of one query name. Counts[i] is the number of statements that took
+// at most Buckets[i] and more than Buckets[i-1], the last entry counts the ones slower than every bucket.
+type QueryStats struct {
+	Count         int64
+	Errors        int64
+	RowsAffected  int64
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+	Buckets       []time.Duration
+	Counts        []int64
+}
+
+// QueryMetrics is an in-memory QueryHook that keeps a latency histogram, an error counter and the
+// rows affected per query name. Collectors such as prometheus can read it with Snapshot, or implement QueryHook directly.
+type QueryMetrics struct {
+	mu      *sync.Mutex
+	buckets []time.Duration
+	stats   map[string]*QueryStats
+}
+
+// NewQueryMetrics creates a QueryMetrics with the given latency buckets, DefaultLatencyBuckets is used if none is given.
+func NewQueryMetrics(buckets ...time.Duration) *QueryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	sorted := append([]time.Duration{}, buckets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &QueryMetrics{
+		mu:      &sync.Mutex{},
+		buckets: sorted,
+		stats:   map[string]*QueryStats{},
+	}
+}
+
+func (m *QueryMetrics) AfterQuery(ctx context.Context, event QueryEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.stats[event.Name]
+	if !ok {
+		stats = &QueryStats{
+			Buckets: m.buckets,
+			Counts:  make([]int64, len(m.buckets)+1),
+		}
+		m.stats[event.Name] = stats
+	}
+
+	stats.Count++
+	stats.TotalDuration += event.Duration
+	if event.Duration > stats.MaxDuration {
+		stats.MaxDuration = event.Duration
+	}
+	if event.Err != nil {
+		stats.Errors++
+	}
+	if event.RowsAffected > 0 {
+		stats.RowsAffected += event.RowsAffected
+	}
+
+	i := sort.Search(len(m.buckets), func(i int) bool { return event.Duration <= m.buckets[i] })
+	stats.Counts[i]++
+}
+
+// Snapshot returns a copy of the metrics collected so far, keyed by query name.
+func (m *QueryMetrics) Snapshot() map[string]QueryStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]QueryStats, len(m.stats))
+	for name, stats := range m.stats {
+		s := *stats
+		s.Counts = append([]int64{}, stats.Counts...)
+		snapshot[name] = s
+	}
+
+	return snapshot
+}
@@ -0,0 +1,48 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_sql_QueryMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Warn(gomock.Any(), gomock.Any()).Times(1)
+
+	mockDB, mock, _ := sqlmock.New()
+	metrics := NewQueryMetrics(10*time.Millisecond, time.Second)
+
+	db := Init(Config{
+		Driver:             "postgres",
+		Leader:             ConnConfig{MockDB: mockDB},
+		SlowQueryThreshold: 20 * time.Millisecond,
+		QueryHooks:         []QueryHook{metrics},
+	}, logger)
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE users").WillDelayFor(30 * time.Millisecond).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE users").WillReturnError(fmt.Errorf("connection reset"))
+
+	for i := 0; i < 3; i++ {
+		_, _ = db.Exec(context.Background(), "deactivate users", "UPDATE users SET status = -1")
+	}
+
+	stats := metrics.Snapshot()["deactivate users"]
+	assert.Equal(t, int64(3), stats.Count)
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Equal(t, int64(5), stats.RowsAffected)
+	assert.Equal(t, int64(2), stats.Counts[0])
+	assert.Equal(t, int64(1), stats.Counts[1])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,8 +3,10 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"sync"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
 )
 
 type NamedCommandStmt interface {
@@ -16,33 +18,63 @@ type NamedCommandStmt interface {
 }
 
 type namedCommandStmt struct {
-	ctx  context.Context
-	stmt *sqlx.NamedStmt
+	ctx   context.Context
+	name  string
+	stmt  *sqlx.NamedStmt
+	cache *stmtCache // cache is set when the statement is held from the statement cache.
+	entry *stmtCacheEntry
+	once  *sync.Once
 }
 
-func initNamedStmt(ctx context.Context, stmt *sqlx.NamedStmt) NamedCommandStmt {
+func initNamedStmt(ctx context.Context, name string, stmt *sqlx.NamedStmt) NamedCommandStmt {
 	return &namedCommandStmt{
 		ctx:  ctx,
+		name: name,
 		stmt: stmt,
 	}
 }
 
+func initCachedNamedStmt(ctx context.Context, name string, cache *stmtCache, entry *stmtCacheEntry) NamedCommandStmt {
+	return &namedCommandStmt{
+		ctx:   ctx,
+		name:  name,
+		stmt:  entry.named,
+		cache: cache,
+		entry: entry,
+		once:  &sync.Once{},
+	}
+}
+
+// Close closes the statement, a cached statement is released back to the cache instead.
 func (n *namedCommandStmt) Close() error {
-	return n.stmt.Close()
+	if n.cache != nil {
+		var err error
+		n.once.Do(func() { err = n.cache.release(n.entry) })
+		return wrapError(err, codes.CodeSQL, n.name)
+	}
+	return wrapError(n.stmt.Close(), codes.CodeSQL, n.name)
 }
 
 func (n *namedCommandStmt) Get(dest interface{}, arg interface{}) error {
-	return n.stmt.GetContext(n.ctx, dest, arg)
+	err := n.stmt.GetContext(n.ctx, dest, arg)
+	n.cache.evict(n.entry, err)
+	return wrapError(err, codes.CodeSQLRead, n.name)
 }
 
 func (n *namedCommandStmt) QueryRow(arg interface{}) *sqlx.Row {
-	return n.stmt.QueryRowxContext(n.ctx, arg)
+	row := n.stmt.QueryRowxContext(n.ctx, arg)
+	n.cache.evict(n.entry, row.Err())
+	return row
 }
 
 func (n *namedCommandStmt) Query(arg interface{}) (*sqlx.Rows, error) {
-	return n.stmt.QueryxContext(n.ctx, arg)
+	rows, err := n.stmt.QueryxContext(n.ctx, arg)
+	n.cache.evict(n.entry, err)
+	return rows, wrapError(err, codes.CodeSQLRead, n.name)
 }
 
 func (n *namedCommandStmt) Exec(arg interface{}) (sql.Result, error) {
-	return n.stmt.ExecContext(n.ctx, arg)
+	res, err := n.stmt.ExecContext(n.ctx, arg)
+	n.cache.evict(n.entry, err)
+	return res, wrapError(err, codes.CodeSQL, n.name)
 }
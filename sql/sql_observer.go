@@ -0,0 +1,104 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/reyhanmichiels/go-pkg/v2/log"
+)
+
+const (
+	slowQueryLogMessage = "slow query: %s, took %s, with query string: %s"
+)
+
+// QueryEvent describes a finished statement. RowsAffected is -1 when the statement does not report it.
+type QueryEvent struct {
+	Name         string
+	Query        string
+	Args         []interface{}
+	Duration     time.Duration
+	RowsAffected int64
+	Err          error
+}
+
+// QueryHook is called after every statement executed through Command and CommandTx,
+// e.g. to feed a metrics collector. It is called synchronously, so it must be fast and safe for concurrent use.
+type QueryHook interface {
+	AfterQuery(ctx context.Context, event QueryEvent)
+}
+
+// observer logs statements and reports them to the query hooks.
+type observer struct {
+	log           log.Interface
+	logQuery      bool
+	slowThreshold time.Duration
+	hooks         []QueryHook
+	renderer      *queryRenderer
+}
+
+func initObserver(log log.Interface, cfg Config) *observer {
+	return &observer{
+		log:           log,
+		logQuery:      cfg.LogQuery,
+		slowThreshold: cfg.SlowQueryThreshold,
+		hooks:         cfg.QueryHooks,
+		renderer:      initQueryRenderer(cfg.Driver, cfg.LogOptions),
+	}
+}
+
+// observe logs the statement and returns a function that must be called once the statement finished.
+func (o *observer) observe(ctx context.Context, name string, query string, args ...interface{}) func(rowsAffected int64, err error) {
+	return o.start(ctx, name, query, args, func() string {
+		return o.renderer.render(query, args...)
+	})
+}
+
+// observeNamed is observe for statements with `:name` bindvars.
+func (o *observer) observeNamed(ctx context.Context, name string, query string, arg interface{}) func(rowsAffected int64, err error) {
+	return o.start(ctx, name, query, []interface{}{arg}, func() string {
+		return o.renderer.renderNamed(query, arg)
+	})
+}
+
+func (o *observer) start(ctx context.Context, name string, query string, args []interface{}, render func() string) func(rowsAffected int64, err error) {
+	if o.logQuery {
+		o.log.Info(ctx, fmt.Sprintf(queryLogMessage, name, render()))
+	}
+
+	start := time.Now()
+	return func(rowsAffected int64, err error) {
+		elapsed := time.Since(start)
+
+		if o.slowThreshold > 0 && elapsed >= o.slowThreshold {
+			o.log.Warn(ctx, fmt.Sprintf(slowQueryLogMessage, name, elapsed, render()))
+		}
+
+		event := QueryEvent{
+			Name:         name,
+			Query:        query,
+			Args:         args,
+			Duration:     elapsed,
+			RowsAffected: rowsAffected,
+			Err:          err,
+		}
+		for _, h := range o.hooks {
+			h.AfterQuery(ctx, event)
+		}
+	}
+}
+
+// rowsAffected returns the rows affected by a statement, or -1 if it is unknown.
+func rowsAffected(res sql.Result, err error) int64 {
+	if err != nil || res == nil {
+		return -1
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
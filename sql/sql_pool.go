@@ -0,0 +1,91 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/reyhanmichiels/go-pkg/v2/operator"
+)
+
+const (
+	defaultPoolMonitorInterval = 30 * time.Second
+
+	poolSaturatedLogMessage = "SQL: [%s] db=%s @%s:%v connection pool saturated, %d new waits for %s in the last %s, in use %d of max %d"
+)
+
+// PoolMonitorConfig enables a periodic check that warns when queries had to wait for a free connection.
+type PoolMonitorConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	// WaitCountThreshold is the number of new waits within one interval that triggers a warning, defaults to 1.
+	WaitCountThreshold int64
+}
+
+// Stats holds the connection pool statistics of every node.
+type Stats struct {
+	Leader    sql.DBStats
+	Followers []sql.DBStats
+}
+
+func (s *sqlDB) Stats() Stats {
+	stats := Stats{
+		Leader:    s.leader.command.Stats(),
+		Followers: make([]sql.DBStats, 0, len(s.followers)),
+	}
+
+	for _, f := range s.followers {
+		stats.Followers = append(stats.Followers, f.command.Stats())
+	}
+
+	return stats
+}
+
+// startPoolMonitor compares the pool statistics of every node periodically until Stop is called.
+func (s *sqlDB) startPoolMonitor() {
+	if !s.cfg.PoolMonitor.Enabled {
+		return
+	}
+
+	interval := operator.Ternary(s.cfg.PoolMonitor.Interval <= 0, defaultPoolMonitorInterval, s.cfg.PoolMonitor.Interval)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		nodes := append([]*node{s.leader}, s.followers...)
+		last := make([]sql.DBStats, len(nodes))
+		for i, n := range nodes {
+			last[i] = n.command.Stats()
+		}
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				for i, n := range nodes {
+					current := n.command.Stats()
+					s.checkPoolSaturation(n, last[i], current, interval)
+					last[i] = current
+				}
+			}
+		}
+	}()
+}
+
+func (s *sqlDB) checkPoolSaturation(n *node, last sql.DBStats, current sql.DBStats, interval time.Duration) {
+	threshold := operator.Ternary(s.cfg.PoolMonitor.WaitCountThreshold <= 0, 1, s.cfg.PoolMonitor.WaitCountThreshold)
+
+	waits := current.WaitCount - last.WaitCount
+	if waits < threshold {
+		return
+	}
+
+	waited := current.WaitDuration - last.WaitDuration
+	s.log.Warn(context.Background(), fmt.Sprintf(poolSaturatedLogMessage, n.role, n.conf.DB, n.conf.Host, n.conf.Port, waits, waited, interval, current.InUse, current.MaxOpenConnections))
+}
@@ -0,0 +1,86 @@
+package sql
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/reyhanmichiels/go-pkg/v2/operator"
+)
+
+const (
+	defaultRetryInitialBackoff = 50 * time.Millisecond
+	defaultRetryMaxBackoff     = time.Second
+
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+	mysqlDeadlock          = 1213
+)
+
+// RetryPolicy re-runs a whole transaction when it fails with a retryable error.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one, retry is disabled if it is less than 2.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// IsRetryable overrides the default classifier, which retries serialization failures and deadlocks of the driver.
+	IsRetryable func(err error) bool
+}
+
+// retry runs fn until it succeeds, fails with a non retryable error, runs out of attempts or the context is done.
+func retry(ctx context.Context, driver string, policy RetryPolicy, fn func() error) error {
+	isRetryable := policy.IsRetryable
+	if isRetryable == nil {
+		isRetryable = func(err error) bool {
+			return isRetryableError(driver, err)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= policy.MaxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(policy.backoff(attempt)):
+		}
+	}
+}
+
+// backoff doubles the wait on every attempt up to MaxBackoff, with a random jitter of up to half of it
+// so that transactions which conflicted with each other do not retry at the same time.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	initial := operator.Ternary(p.InitialBackoff <= 0, defaultRetryInitialBackoff, p.InitialBackoff)
+	maxBackoff := operator.Ternary(p.MaxBackoff <= 0, defaultRetryMaxBackoff, p.MaxBackoff)
+
+	d := initial << (attempt - 1)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)) // nolint:gosec
+}
+
+func isRetryableError(driver string, err error) bool {
+	switch driver {
+	case "postgres":
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+		}
+	case "mysql":
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) {
+			return mysqlErr.Number == mysqlDeadlock
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_sql_isRetryableError(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+		err    error
+		want   bool
+	}{
+		{
+			name:   "postgres serialization failure",
+			driver: "postgres",
+			err:    &pq.Error{Code: "40001"},
+			want:   true,
+		},
+		{
+			name:   "postgres deadlock",
+			driver: "postgres",
+			err:    &pq.Error{Code: "40P01"},
+			want:   true,
+		},
+		{
+			name:   "postgres unique violation",
+			driver: "postgres",
+			err:    &pq.Error{Code: "23505"},
+			want:   false,
+		},
+		{
+			name:   "mysql deadlock",
+			driver: "mysql",
+			err:    &mysql.MySQLError{Number: 1213},
+			want:   true,
+		},
+		{
+			name:   "other error",
+			driver: "mysql",
+			err:    errors.New("connection refused"),
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryableError(tt.driver, tt.err))
+		})
+	}
+}
+
+func Test_sql_TransactionRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts").WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	db := Init(Config{
+		Driver: "postgres",
+		Leader: ConnConfig{MockDB: mockDB},
+	}, logger)
+
+	txOpts := TxOptions{
+		Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}
+
+	attempts := 0
+	err := db.Transaction(context.Background(), "transfer", txOpts, func(ctx context.Context) error {
+		attempts++
+		_, err := db.Exec(ctx, "debit account", "UPDATE accounts SET balance = balance - 10 WHERE id = 1")
+		return err
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
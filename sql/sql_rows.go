@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Rows are the rows of a Query. The statement context is released when the rows are closed
+// or read until the end, so they must be closed like *sqlx.Rows.
+//
+// Breaking change: Query used to return *sqlx.Rows. Rows embeds it, so its methods are unchanged, but code
+// that names the type must use *sql.Rows. Reading Rows.Rows directly leaves the statement context to expire.
+type Rows struct {
+	*sqlx.Rows
+	cancel context.CancelFunc
+}
+
+func initRows(rows *sqlx.Rows, cancel context.CancelFunc) *Rows {
+	if rows == nil {
+		cancel()
+		return nil
+	}
+
+	return &Rows{Rows: rows, cancel: cancel}
+}
+
+func (r *Rows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+
+	r.cancel()
+	return false
+}
+
+func (r *Rows) Close() error {
+	defer r.cancel()
+	return r.Rows.Close()
+}
+
+// Row is the row of a QueryRow. The statement context is released when the row is scanned.
+//
+// Breaking change: QueryRow used to return *sqlx.Row. Row embeds it, so its methods are unchanged, but code
+// that names the type must use *sql.Row. Scanning Row.Row directly leaves the statement context to expire.
+type Row struct {
+	*sqlx.Row
+	cancel context.CancelFunc
+}
+
+func initRow(row *sqlx.Row, cancel context.CancelFunc) *Row {
+	if row.Err() != nil {
+		cancel()
+	}
+	return &Row{Row: row, cancel: cancel}
+}
+
+func (r *Row) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return r.Row.Scan(dest...)
+}
+
+func (r *Row) StructScan(dest interface{}) error {
+	defer r.cancel()
+	return r.Row.StructScan(dest)
+}
+
+func (r *Row) MapScan(dest map[string]interface{}) error {
+	defer r.cancel()
+	return r.Row.MapScan(dest)
+}
+
+func (r *Row) SliceScan() ([]interface{}, error) {
+	defer r.cancel()
+	return r.Row.SliceScan()
+}
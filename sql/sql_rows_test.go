@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sql_Rows_cancel(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(rows *Rows)
+	}{
+		{
+			name: "closed",
+			read: func(rows *Rows) { assert.NoError(t, rows.Close()) },
+		},
+		{
+			name: "read until the end",
+			read: func(rows *Rows) {
+				for rows.Next() {
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, _ := sqlmock.New()
+			mock.ExpectQuery("SELECT id FROM users").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+			r, err := sqlx.NewDb(mockDB, "postgres").Queryx("SELECT id FROM users")
+			assert.NoError(t, err)
+
+			canceled := false
+			rows := initRows(r, func() { canceled = true })
+			assert.False(t, canceled)
+
+			tt.read(rows)
+			assert.True(t, canceled)
+		})
+	}
+}
+
+func Test_sql_Row_cancel(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT id FROM users").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	canceled := false
+	row := initRow(sqlx.NewDb(mockDB, "postgres").QueryRowx("SELECT id FROM users"), func() { canceled = true })
+	assert.False(t, canceled)
+
+	var id int64
+	assert.NoError(t, row.Scan(&id))
+	assert.Equal(t, int64(1), id)
+	assert.True(t, canceled)
+}
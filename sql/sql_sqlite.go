@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+)
+
+// sqliteDriver is the driver name registered by the pure-Go SQLite drivers, e.g. modernc.org/sqlite.
+// The driver is not imported by this package, so services that do not use SQLite don't link it in.
+// Import it in the application:
+//
+//	import _ "modernc.org/sqlite"
+const sqliteDriver = "sqlite"
+
+// sqliteInMemory is the DB name of an in-memory database.
+const sqliteInMemory = ":memory:"
+
+// sqlite error messages, they are the same for every driver as they come from SQLite itself.
+const (
+	sqliteUniqueViolation     = "UNIQUE constraint failed"
+	sqliteForeignKeyViolation = "FOREIGN KEY constraint failed"
+	sqliteNotNullViolation    = "NOT NULL constraint failed"
+)
+
+func init() {
+	// sqlx does not know the pure-Go driver name, so Rebind would leave queries untouched for it.
+	sqlx.BindDriver(sqliteDriver, sqlx.QUESTION)
+}
+
+// sqliteURI uses ConnConfig.DB as the database file, which is created when it does not exist.
+func sqliteURI(conf ConnConfig) string {
+	if isSQLiteInMemory(conf) {
+		return "file::memory:?_pragma=foreign_keys(1)"
+	}
+	return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", conf.DB)
+}
+
+// isSQLiteInMemory reports whether conf is an in-memory database. Every connection to it opens a new,
+// empty database, so the pool is limited to a single connection that is never closed.
+func isSQLiteInMemory(conf ConnConfig) bool {
+	return conf.DSN == "" && (conf.DB == "" || conf.DB == sqliteInMemory)
+}
+
+func classifySQLiteError(err error) (codes.Code, bool) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, sqliteUniqueViolation):
+		return codes.CodeSQLUniqueConstraint, true
+	case strings.Contains(msg, sqliteForeignKeyViolation):
+		return codes.CodeSQLForeignKeyViolation, true
+	case strings.Contains(msg, sqliteNotNullViolation):
+		return codes.CodeSQLNotNullViolation, true
+	}
+	return codes.NoCode, false
+}
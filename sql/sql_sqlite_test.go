@@ -0,0 +1,46 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	_ "modernc.org/sqlite"
+)
+
+func Test_sql_sqlite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	db, err := InitWithError(Config{Driver: "sqlite", Leader: ConnConfig{DB: ":memory:"}}, logger)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Stop()
+
+	ctx := context.Background()
+	_, err = db.Leader().Exec(ctx, "create users", "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL UNIQUE)")
+	assert.NoError(t, err)
+
+	insert := db.Rebind("INSERT INTO users (id, email) VALUES (?, ?)")
+	_, err = db.Leader().Exec(ctx, "insert user", insert, 1, "john@mail.com")
+	assert.NoError(t, err)
+
+	var email string
+	err = db.Get(ctx, "get user", db.Rebind("SELECT email FROM users WHERE id = ?"), &email, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "john@mail.com", email)
+
+	_, err = db.Leader().Exec(ctx, "insert user", insert, 2, "john@mail.com")
+	assert.Equal(t, codes.CodeSQLUniqueConstraint, errors.GetCode(err))
+
+	err = db.Get(ctx, "get user", db.Rebind("SELECT email FROM users WHERE id = ?"), &email, 3)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,72 @@
+package sql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type stickyKey struct{} // stickyKey is a context key for the read-your-writes state.
+
+type stickyState struct {
+	mu      *sync.Mutex
+	leader  bool
+	wroteAt time.Time
+}
+
+// WithReadYourWrites returns a context that remembers writes made through it, so that subsequent
+// reads on the same context are routed to the leader instead of a possibly lagging follower.
+// It is meant to be called once per request, e.g. in a middleware.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(stickyKey{}).(*stickyState); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, stickyKey{}, &stickyState{mu: &sync.Mutex{}})
+}
+
+// WithLeader returns a context whose reads are always routed to the leader.
+func WithLeader(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stickyKey{}, &stickyState{mu: &sync.Mutex{}, leader: true})
+}
+
+// markWrite records a write on the context, it is a no-op if the context does not track writes.
+func (s *sqlDB) markWrite(ctx context.Context) {
+	state, ok := ctx.Value(stickyKey{}).(*stickyState)
+	if !ok {
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.wroteAt = time.Now()
+}
+
+// isSticky reports whether reads on the context must go to the leader. Once a write happened,
+// reads stick to the leader for Config.StickyLeaderWindow, or for the rest of the context if it is zero.
+func (s *sqlDB) isSticky(ctx context.Context) bool {
+	state, ok := ctx.Value(stickyKey{}).(*stickyState)
+	if !ok {
+		return false
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.leader {
+		return true
+	}
+
+	if state.wroteAt.IsZero() {
+		return false
+	}
+
+	return s.cfg.StickyLeaderWindow <= 0 || time.Since(state.wroteAt) < s.cfg.StickyLeaderWindow
+}
+
+// reader returns the command used to serve reads on the given context.
+func (s *sqlDB) reader(ctx context.Context) Command {
+	if s.isSticky(ctx) {
+		return s.leader.command
+	}
+	return s.Follower()
+}
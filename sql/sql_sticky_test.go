@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_sql_ReadYourWrites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	leaderDB, leaderMock, _ := sqlmock.New()
+	followerDB, followerMock, _ := sqlmock.New()
+
+	db := Init(Config{
+		Driver:   "postgres",
+		Leader:   ConnConfig{Host: "leader", Port: 5432, MockDB: leaderDB},
+		Follower: ConnConfig{Host: "follower", Port: 5432, MockDB: followerDB},
+	}, logger)
+
+	var name string
+	ctx := WithReadYourWrites(context.Background())
+
+	followerMock.ExpectQuery("SELECT name FROM users").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("stale"))
+	assert.NoError(t, db.Get(ctx, "get user", "SELECT name FROM users", &name))
+	assert.Equal(t, "stale", name)
+
+	leaderMock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err := db.Exec(ctx, "update user", "UPDATE users SET name = 'fresh'")
+	assert.NoError(t, err)
+
+	leaderMock.ExpectQuery("SELECT name FROM users").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("fresh"))
+	assert.NoError(t, db.Get(ctx, "get user", "SELECT name FROM users", &name))
+	assert.Equal(t, "fresh", name)
+
+	followerMock.ExpectQuery("SELECT name FROM users").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("stale"))
+	assert.NoError(t, db.Get(context.Background(), "get user", "SELECT name FROM users", &name))
+	assert.Equal(t, "stale", name)
+
+	assert.NoError(t, leaderMock.ExpectationsWereMet())
+	assert.NoError(t, followerMock.ExpectationsWereMet())
+}
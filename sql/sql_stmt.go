@@ -3,8 +3,10 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"sync"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
 )
 
 type CommandStmt interface {
@@ -16,8 +18,11 @@ type CommandStmt interface {
 }
 
 type commandStmt struct {
-	ctx  context.Context
-	stmt *sqlx.Stmt
+	ctx   context.Context
+	stmt  *sqlx.Stmt
+	cache *stmtCache // cache is set when the statement is held from the statement cache.
+	entry *stmtCacheEntry
+	once  *sync.Once
 }
 
 func initStmt(ctx context.Context, stmt *sqlx.Stmt) CommandStmt {
@@ -29,22 +34,46 @@ func initStmt(ctx context.Context, stmt *sqlx.Stmt) CommandStmt {
 	return c
 }
 
+func initCachedStmt(ctx context.Context, cache *stmtCache, entry *stmtCacheEntry) CommandStmt {
+	return &commandStmt{
+		ctx:   ctx,
+		stmt:  entry.stmt,
+		cache: cache,
+		entry: entry,
+		once:  &sync.Once{},
+	}
+}
+
+// Close closes the statement, a cached statement is released back to the cache instead.
 func (c *commandStmt) Close() error {
-	return c.stmt.Close()
+	if c.cache != nil {
+		var err error
+		c.once.Do(func() { err = c.cache.release(c.entry) })
+		return wrapError(err, codes.CodeSQL, "close statement")
+	}
+	return wrapError(c.stmt.Close(), codes.CodeSQL, "close statement")
 }
 
 func (c *commandStmt) Get(name string, dest interface{}, args ...interface{}) error {
-	return c.stmt.GetContext(c.ctx, dest, args...)
+	err := c.stmt.GetContext(c.ctx, dest, args...)
+	c.cache.evict(c.entry, err)
+	return wrapError(err, codes.CodeSQLRead, name)
 }
 
 func (c *commandStmt) QueryRow(name string, args ...interface{}) (*sqlx.Row, error) {
-	return c.stmt.QueryRowxContext(c.ctx, args...), nil
+	row := c.stmt.QueryRowxContext(c.ctx, args...)
+	c.cache.evict(c.entry, row.Err())
+	return row, wrapError(row.Err(), codes.CodeSQLRead, name)
 }
 
 func (c *commandStmt) Query(name string, args ...interface{}) (*sqlx.Rows, error) {
-	return c.stmt.QueryxContext(c.ctx, args...)
+	rows, err := c.stmt.QueryxContext(c.ctx, args...)
+	c.cache.evict(c.entry, err)
+	return rows, wrapError(err, codes.CodeSQLRead, name)
 }
 
 func (c *commandStmt) Exec(name string, args ...interface{}) (sql.Result, error) {
-	return c.stmt.ExecContext(c.ctx, args...)
+	res, err := c.stmt.ExecContext(c.ctx, args...)
+	c.cache.evict(c.entry, err)
+	return res, wrapError(err, codes.CodeSQL, name)
 }
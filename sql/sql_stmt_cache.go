@@ -0,0 +1,211 @@
+package sql
+
+import (
+	"container/list"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+)
+
+const (
+	defaultStmtCacheSize = 100
+
+	pqFeatureNotSupported = "0A000" // e.g. `cached plan must not change result type` after a schema change
+	mysqlNeedReprepare    = 1615
+)
+
+// StmtCacheConfig enables reusing the statements of Command.Prepare and Command.PrepareNamed by name and query.
+// Close releases a cached statement, it is closed once it left the cache and every caller released it.
+type StmtCacheConfig struct {
+	Enabled bool
+	// Size is the maximum number of statements per node, the least recently used one is evicted beyond it. Defaults to 100.
+	Size int
+}
+
+// stmtCache is a LRU cache of prepared statements. Statements prepared on a sql.DB are re-prepared on
+// new connections by database/sql, a statement is only evicted when it cannot be used anymore.
+type stmtCache struct {
+	mu    *sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+// stmtCacheEntry is guarded by the cache mutex. refs counts the callers holding the statement,
+// which is closed when it is evicted and no caller holds it anymore.
+type stmtCacheEntry struct {
+	key     string
+	stmt    *sqlx.Stmt
+	named   *sqlx.NamedStmt
+	refs    int
+	evicted bool
+	closed  bool
+}
+
+// initStmtCache returns nil when the cache is disabled.
+func initStmtCache(cfg StmtCacheConfig) *stmtCache {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	size := cfg.Size
+	if size <= 0 {
+		size = defaultStmtCacheSize
+	}
+
+	return &stmtCache{
+		mu:    &sync.Mutex{},
+		size:  size,
+		order: list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+func stmtCacheKey(kind string, name string, query string) string {
+	return kind + "\x00" + name + "\x00" + query
+}
+
+// get acquires the cached statement of key, it must be released by the caller.
+func (c *stmtCache) get(key string) (*stmtCacheEntry, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(e)
+	entry := e.Value.(*stmtCacheEntry)
+	entry.refs++
+	return entry, true
+}
+
+// add caches entry and acquires the cached one, which is an earlier entry when the same statement
+// was prepared concurrently. Statements that are not returned are closed.
+func (c *stmtCache) add(entry *stmtCacheEntry) *stmtCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[entry.key]; ok {
+		entry.close()
+		c.order.MoveToFront(e)
+		cached := e.Value.(*stmtCacheEntry)
+		cached.refs++
+		return cached
+	}
+
+	entry.refs++
+	c.items[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+
+	return entry
+}
+
+// release gives back a statement acquired by get or add.
+func (c *stmtCache) release(entry *stmtCacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.refs--
+	if entry.evicted && entry.refs <= 0 {
+		return entry.close()
+	}
+	return nil
+}
+
+// evict removes entry from the cache if err shows that it cannot be used anymore.
+// It is closed once every caller released it.
+func (c *stmtCache) evict(entry *stmtCacheEntry, err error) {
+	if c == nil || !isBrokenStmtError(err) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the statement may have been replaced already by another caller that hit the same error
+	if e, ok := c.items[entry.key]; ok && e.Value.(*stmtCacheEntry) == entry {
+		c.remove(e)
+	}
+}
+
+// remove takes e out of the LRU, the statement is closed now only if nobody holds it.
+func (c *stmtCache) remove(e *list.Element) {
+	entry := e.Value.(*stmtCacheEntry)
+	c.order.Remove(e)
+	delete(c.items, entry.key)
+
+	entry.evicted = true
+	if entry.refs <= 0 {
+		entry.close()
+	}
+}
+
+// close closes every cached statement, including the ones still held by callers.
+func (c *stmtCache) close() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(*stmtCacheEntry)
+		entry.evicted = true
+		if err := entry.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.order.Init()
+	c.items = map[string]*list.Element{}
+
+	return firstErr
+}
+
+func (e *stmtCacheEntry) close() error {
+	if e.closed {
+		return nil
+	}
+
+	e.closed = true
+	if e.named != nil {
+		return e.named.Close()
+	}
+	return e.stmt.Close()
+}
+
+func isBrokenStmtError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqFeatureNotSupported
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlNeedReprepare
+	}
+
+	return false
+}
@@ -0,0 +1,83 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sql_stmtCache(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectPrepare("SELECT name FROM users").WillBeClosed()
+	mock.ExpectExec("SELECT name FROM users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SELECT name FROM users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("SELECT email FROM users").WillBeClosed()
+	mock.ExpectClose()
+
+	c := initCommand(sqlx.NewDb(mockDB, "postgres"), nil, &observer{}, nil, initStmtCache(StmtCacheConfig{Enabled: true, Size: 1}))
+	ctx := context.Background()
+
+	// the second prepare reuses the first statement, closing it does nothing
+	for i := 0; i < 2; i++ {
+		stmt, err := c.Prepare(ctx, "get user name", "SELECT name FROM users WHERE id = $1")
+		assert.NoError(t, err)
+		_, err = stmt.Exec("get user name", 1)
+		assert.NoError(t, err)
+		assert.NoError(t, stmt.Close())
+	}
+
+	// the least recently used statement is closed when the cache is full
+	_, err := c.Prepare(ctx, "get user email", "SELECT email FROM users WHERE id = $1")
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Close())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_sql_stmtCache_evict(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectPrepare("SELECT name FROM users").WillBeClosed()
+	mock.ExpectExec("SELECT name FROM users").WillReturnError(&pq.Error{Code: "0A000", Message: "cached plan must not change result type"})
+	mock.ExpectPrepare("SELECT name FROM users")
+
+	c := initCommand(sqlx.NewDb(mockDB, "postgres"), nil, &observer{}, nil, initStmtCache(StmtCacheConfig{Enabled: true}))
+	ctx := context.Background()
+
+	stmt, err := c.Prepare(ctx, "get user name", "SELECT name FROM users WHERE id = $1")
+	assert.NoError(t, err)
+	_, err = stmt.Exec("get user name", 1)
+	assert.Error(t, err)
+	assert.NoError(t, stmt.Close())
+
+	_, err = c.Prepare(ctx, "get user name", "SELECT name FROM users WHERE id = $1")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_sql_stmtCache_evictHeld(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectPrepare("SELECT name FROM users").WillBeClosed()
+	mock.ExpectPrepare("SELECT email FROM users")
+	mock.ExpectExec("SELECT name FROM users").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	c := initCommand(sqlx.NewDb(mockDB, "postgres"), nil, &observer{}, nil, initStmtCache(StmtCacheConfig{Enabled: true, Size: 1}))
+	ctx := context.Background()
+
+	held, err := c.Prepare(ctx, "get user name", "SELECT name FROM users WHERE id = $1")
+	assert.NoError(t, err)
+
+	// evicts the held statement from the cache, it stays usable until it is released
+	_, err = c.Prepare(ctx, "get user email", "SELECT email FROM users WHERE id = $1")
+	assert.NoError(t, err)
+
+	_, err = held.Exec("get user name", 1)
+	assert.NoError(t, err)
+	assert.NoError(t, held.Close())
+	assert.NoError(t, held.Close())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
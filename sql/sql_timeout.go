@@ -0,0 +1,62 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+)
+
+type noQueryTimeoutKey struct{} // noQueryTimeoutKey is a context key exempting statements from the query timeout.
+
+// WithoutQueryTimeout returns a context whose statements are not bound by Config.QueryTimeout or QueryTimeouts,
+// for statements that are expected to run long, e.g. migrations or lock waits. A deadline of ctx still applies.
+func WithoutQueryTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noQueryTimeoutKey{}, true)
+}
+
+// queryTimeout bounds how long a statement may run, by statement name.
+type queryTimeout struct {
+	timeout time.Duration
+	byName  map[string]time.Duration
+}
+
+func initQueryTimeout(cfg Config) *queryTimeout {
+	return &queryTimeout{
+		timeout: cfg.QueryTimeout,
+		byName:  cfg.QueryTimeouts,
+	}
+}
+
+// withTimeout returns the context a statement runs with. A deadline of ctx that is earlier than the timeout is kept.
+func (t *queryTimeout) withTimeout(ctx context.Context, name string) (context.Context, context.CancelFunc) {
+	if t == nil || ctx.Value(noQueryTimeoutKey{}) != nil {
+		return ctx, func() {}
+	}
+
+	timeout, ok := t.byName[name]
+	if !ok {
+		timeout = t.timeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// wrapStmtError works like wrapError, but reports the error as codes.CodeContextDeadlineExceeded when the
+// statement ran out of time, whatever error the driver returned for the canceled statement.
+func wrapStmtError(ctx context.Context, err error, fallback codes.Code, name string) error {
+	if err == nil || errors.GetCode(err) != codes.NoCode {
+		return err
+	}
+
+	code := classifyError(err, fallback)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		code = codes.CodeContextDeadlineExceeded
+	}
+
+	return wrapWithCaller(err, code, name)
+}
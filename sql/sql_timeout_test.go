@@ -0,0 +1,62 @@
+package sql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
+	"github.com/reyhanmichiels/go-pkg/v2/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sql_queryTimeout_withTimeout(t *testing.T) {
+	timeout := initQueryTimeout(Config{
+		QueryTimeout: time.Second,
+		QueryTimeouts: map[string]time.Duration{
+			"report":   time.Minute,
+			"no limit": 0,
+		},
+	})
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		wantDeadline bool
+		want         time.Duration
+	}{
+		{name: "get user", ctx: context.Background(), wantDeadline: true, want: time.Second},
+		{name: "report", ctx: context.Background(), wantDeadline: true, want: time.Minute},
+		{name: "no limit", ctx: context.Background(), wantDeadline: false},
+		{name: "migrate up", ctx: WithoutQueryTimeout(context.Background()), wantDeadline: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := timeout.withTimeout(tt.ctx, tt.name)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			assert.Equal(t, tt.wantDeadline, ok)
+			if ok {
+				assert.InDelta(t, tt.want, time.Until(deadline), float64(100*time.Millisecond))
+			}
+		})
+	}
+}
+
+func Test_sql_command_Exec_timeout(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	mock.ExpectExec("UPDATE users").WillDelayFor(time.Second).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	c := initCommand(sqlx.NewDb(mockDB, "postgres"), nil, &observer{}, initQueryTimeout(Config{QueryTimeout: 10 * time.Millisecond}), nil)
+
+	_, err := c.Exec(context.Background(), "update user", "UPDATE users SET name = $1", "john")
+	assert.Equal(t, codes.CodeContextDeadlineExceeded, errors.GetCode(err))
+
+	// the error points at the command method, not at the helper wrapping it
+	file, _, _, _ := errors.GetCaller(err)
+	assert.True(t, strings.HasSuffix(file, "/sql_command.go"), file)
+}
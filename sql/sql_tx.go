@@ -3,9 +3,9 @@ package sql
 import (
 	"context"
 	"database/sql"
-	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/reyhanmichiels/go-pkg/v2/codes"
 	"github.com/reyhanmichiels/go-pkg/v2/errors"
 	"github.com/reyhanmichiels/go-pkg/v2/log"
 )
@@ -14,38 +14,47 @@ type CommandTx interface {
 	Commit() error
 	Rollback()
 
-	QueryRow(name string, query string, args ...interface{}) (*sqlx.Row, error)
-	Query(name string, query string, args ...interface{}) (*sqlx.Rows, error)
+	Savepoint(name string) error
+	RollbackToSavepoint(name string) error
+	ReleaseSavepoint(name string) error
+
+	QueryRow(name string, query string, args ...interface{}) (*Row, error)
+	Query(name string, query string, args ...interface{}) (*Rows, error)
 	Get(name string, query string, dest interface{}, args ...interface{}) error
+	Select(name string, query string, dest interface{}, args ...interface{}) error
 
 	Prepare(name string, query string) (CommandStmt, error)
 	PrepareNamed(name string, query string) (NamedCommandStmt, error)
 	NamedExec(name string, query string, args interface{}) (sql.Result, error)
 	Exec(name string, query string, args ...interface{}) (sql.Result, error)
+	BulkInsert(name string, table string, rows interface{}) (int64, error)
+	Upsert(name string, table string, rows interface{}, opt UpsertOptions) (int64, error)
 }
 
 type commandTx struct {
-	ctx      context.Context
-	name     string
-	tx       *sqlx.Tx
-	log      log.Interface
-	logQuery bool
+	ctx     context.Context
+	name    string
+	tx      *sqlx.Tx
+	log     log.Interface
+	obs     *observer
+	timeout *queryTimeout
 }
 
-func initTx(ctx context.Context, name string, tx *sqlx.Tx, log log.Interface, logQuery bool) CommandTx {
+func initTx(ctx context.Context, name string, tx *sqlx.Tx, log log.Interface, obs *observer, timeout *queryTimeout) CommandTx {
 	c := &commandTx{
-		ctx:      ctx,
-		name:     name,
-		tx:       tx,
-		log:      log,
-		logQuery: logQuery,
+		ctx:     ctx,
+		name:    name,
+		tx:      tx,
+		log:     log,
+		obs:     obs,
+		timeout: timeout,
 	}
 
 	return c
 }
 
 func (x *commandTx) Commit() error {
-	return x.tx.Commit()
+	return wrapError(x.tx.Commit(), codes.CodeSQLTxCommit, x.name)
 }
 
 // Rollback needs to be called with defer right after calling BeginTx.
@@ -56,60 +65,101 @@ func (x *commandTx) Rollback() {
 	}
 }
 
-func (x *commandTx) QueryRow(name string, query string, args ...interface{}) (*sqlx.Row, error) {
-	if x.logQuery {
-		x.log.Info(x.ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query, args...)))
-	}
-	row := x.tx.QueryRowxContext(x.ctx, query, args...)
-	return row, row.Err()
+// Savepoint marks a point inside the transaction that can be rolled back to without aborting the
+// whole transaction. The name is used as is, so it must be a valid identifier.
+func (x *commandTx) Savepoint(name string) error {
+	_, err := x.Exec(x.name, "SAVEPOINT "+name)
+	return err
 }
 
-func (x *commandTx) Query(name string, query string, args ...interface{}) (*sqlx.Rows, error) {
-	if x.logQuery {
-		x.log.Info(x.ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query, args...)))
-	}
-	return x.tx.QueryxContext(x.ctx, query, args...)
+func (x *commandTx) RollbackToSavepoint(name string) error {
+	_, err := x.Exec(x.name, "ROLLBACK TO SAVEPOINT "+name)
+	return err
+}
+
+func (x *commandTx) ReleaseSavepoint(name string) error {
+	_, err := x.Exec(x.name, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+func (x *commandTx) QueryRow(name string, query string, args ...interface{}) (*Row, error) {
+	stmtCtx, cancel := x.timeout.withTimeout(x.ctx, name) // released once the row is scanned
+	done := x.obs.observe(x.ctx, name, query, args...)
+	row := x.tx.QueryRowxContext(stmtCtx, query, args...)
+	done(-1, row.Err())
+	return initRow(row, cancel), wrapStmtError(stmtCtx, row.Err(), codes.CodeSQLRead, name)
+}
+
+func (x *commandTx) Query(name string, query string, args ...interface{}) (*Rows, error) {
+	stmtCtx, cancel := x.timeout.withTimeout(x.ctx, name) // released once the rows are closed
+	done := x.obs.observe(x.ctx, name, query, args...)
+	rows, err := x.tx.QueryxContext(stmtCtx, query, args...)
+	done(-1, err)
+	return initRows(rows, cancel), wrapStmtError(stmtCtx, err, codes.CodeSQLRead, name)
 }
 
 func (x *commandTx) NamedExec(name string, query string, args interface{}) (sql.Result, error) {
-	if x.logQuery {
-		x.log.Info(x.ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query)))
-	}
-	return x.tx.NamedExecContext(x.ctx, query, args)
+	stmtCtx, cancel := x.timeout.withTimeout(x.ctx, name)
+	defer cancel()
+
+	done := x.obs.observeNamed(x.ctx, name, query, args)
+	res, err := x.tx.NamedExecContext(stmtCtx, query, args)
+	done(rowsAffected(res, err), err)
+	return res, wrapStmtError(stmtCtx, err, codes.CodeSQLTxExec, name)
 }
 
 func (x *commandTx) Prepare(name string, query string) (CommandStmt, error) {
-	if x.logQuery {
-		x.log.Info(x.ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query)))
-	}
-	stmt, err := x.tx.PreparexContext(x.ctx, query)
+	stmtCtx, cancel := x.timeout.withTimeout(x.ctx, name)
+	defer cancel()
+
+	done := x.obs.observe(x.ctx, name, query)
+	stmt, err := x.tx.PreparexContext(stmtCtx, query)
+	done(-1, err)
 	if err != nil {
-		return nil, err
+		return nil, wrapStmtError(stmtCtx, err, codes.CodeSQLPrepareStmt, name)
 	}
 	return initStmt(x.ctx, stmt), nil
 }
 
 func (x *commandTx) PrepareNamed(name string, query string) (NamedCommandStmt, error) {
-	if x.logQuery {
-		x.log.Info(x.ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query)))
-	}
-	stmt, err := x.tx.PrepareNamedContext(x.ctx, query)
+	stmtCtx, cancel := x.timeout.withTimeout(x.ctx, name)
+	defer cancel()
+
+	done := x.obs.observeNamed(x.ctx, name, query, nil)
+	stmt, err := x.tx.PrepareNamedContext(stmtCtx, query)
+	done(-1, err)
 	if err != nil {
-		return nil, err
+		return nil, wrapStmtError(stmtCtx, err, codes.CodeSQLPrepareStmt, name)
 	}
-	return initNamedStmt(x.ctx, stmt), nil
+	return initNamedStmt(x.ctx, name, stmt), nil
 }
 
 func (x *commandTx) Exec(name string, query string, args ...interface{}) (sql.Result, error) {
-	if x.logQuery {
-		x.log.Info(x.ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query, args...)))
-	}
-	return x.tx.ExecContext(x.ctx, query, args...)
+	stmtCtx, cancel := x.timeout.withTimeout(x.ctx, name)
+	defer cancel()
+
+	done := x.obs.observe(x.ctx, name, query, args...)
+	res, err := x.tx.ExecContext(stmtCtx, query, args...)
+	done(rowsAffected(res, err), err)
+	return res, wrapStmtError(stmtCtx, err, codes.CodeSQLTxExec, name)
 }
 
 func (x *commandTx) Get(name string, query string, dest interface{}, args ...interface{}) error {
-	if x.logQuery {
-		x.log.Info(x.ctx, fmt.Sprintf(queryLogMessage, name, replaceBindVarsWithArgs(query, args...)))
-	}
-	return x.tx.GetContext(x.ctx, dest, query, args...)
+	stmtCtx, cancel := x.timeout.withTimeout(x.ctx, name)
+	defer cancel()
+
+	done := x.obs.observe(x.ctx, name, query, args...)
+	err := x.tx.GetContext(stmtCtx, dest, query, args...)
+	done(-1, err)
+	return wrapStmtError(stmtCtx, err, codes.CodeSQLRead, name)
+}
+
+func (x *commandTx) Select(name string, query string, dest interface{}, args ...interface{}) error {
+	stmtCtx, cancel := x.timeout.withTimeout(x.ctx, name)
+	defer cancel()
+
+	done := x.obs.observe(x.ctx, name, query, args...)
+	err := x.tx.SelectContext(stmtCtx, dest, query, args...)
+	done(-1, err)
+	return wrapStmtError(stmtCtx, err, codes.CodeSQLRead, name)
 }
@@ -0,0 +1,81 @@
+package sql
+
+import (
+	"context"
+	"sync"
+)
+
+type txHooksKey struct{} // txHooksKey is a context key for the hooks of the current transaction scope.
+
+// txHooks holds the callbacks registered in one transaction scope, nested transactions have their own scope.
+type txHooks struct {
+	mu         *sync.Mutex
+	onCommit   []func(ctx context.Context)
+	onRollback []func(ctx context.Context)
+}
+
+func initTxHooks() *txHooks {
+	return &txHooks{mu: &sync.Mutex{}}
+}
+
+// OnCommit registers fn to run after the transaction in ctx, started with Interface.Transaction, is committed,
+// e.g. to publish an event or invalidate a cache only once the change is visible. Callbacks run in registration
+// order with the context the transaction was started with. Without a transaction in ctx, fn runs right away.
+// Callbacks of a nested transaction only run when the outer transaction commits.
+func OnCommit(ctx context.Context, fn func(ctx context.Context)) {
+	hooks, ok := ctx.Value(txHooksKey{}).(*txHooks)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.onCommit = append(hooks.onCommit, fn)
+}
+
+// OnRollback registers fn to run after the transaction in ctx is rolled back, including a commit that failed.
+// Callbacks of a nested transaction also run when only its savepoint is rolled back.
+// Without a transaction in ctx, fn is never called.
+func OnRollback(ctx context.Context, fn func(ctx context.Context)) {
+	hooks, ok := ctx.Value(txHooksKey{}).(*txHooks)
+	if !ok {
+		return
+	}
+
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.onRollback = append(hooks.onRollback, fn)
+}
+
+// merge moves the callbacks of a released nested transaction to its parent.
+func (h *txHooks) merge(child *txHooks) {
+	child.mu.Lock()
+	defer child.mu.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onCommit = append(h.onCommit, child.onCommit...)
+	h.onRollback = append(h.onRollback, child.onRollback...)
+}
+
+func (h *txHooks) runCommit(ctx context.Context) {
+	h.mu.Lock()
+	callbacks := h.onCommit
+	h.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(ctx)
+	}
+}
+
+func (h *txHooks) runRollback(ctx context.Context) {
+	h.mu.Lock()
+	callbacks := h.onRollback
+	h.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(ctx)
+	}
+}
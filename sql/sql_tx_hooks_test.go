@@ -0,0 +1,103 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mock_log "github.com/reyhanmichiels/go-pkg/v2/tests/mock/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_sql_OnCommit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mock_log.NewMockInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	tests := []struct {
+		name     string
+		prepMock func(mock sqlmock.Sqlmock)
+		innerErr error
+		outerErr error
+		want     []string
+	}{
+		{
+			name: "commit runs commit callbacks in order",
+			prepMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			want: []string{"outer committed", "inner committed"},
+		},
+		{
+			name: "rolled back savepoint runs its rollback callbacks only",
+			prepMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			innerErr: errors.New("payment declined"),
+			want:     []string{"inner rolled back", "outer committed"},
+		},
+		{
+			name: "rollback runs rollback callbacks",
+			prepMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			outerErr: errors.New("order rejected"),
+			want:     []string{"outer rolled back", "inner rolled back"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, _ := sqlmock.New()
+			tt.prepMock(mock)
+
+			db := Init(Config{
+				Driver: "postgres",
+				Leader: ConnConfig{MockDB: mockDB},
+			}, logger)
+
+			got := []string{}
+			record := func(event string) func(context.Context) {
+				return func(context.Context) {
+					got = append(got, event)
+				}
+			}
+
+			_ = db.Transaction(context.Background(), "create order", TxOptions{}, func(ctx context.Context) error {
+				OnCommit(ctx, record("outer committed"))
+				OnRollback(ctx, record("outer rolled back"))
+
+				_ = db.Transaction(ctx, "create payment", TxOptions{}, func(ctx context.Context) error {
+					OnCommit(ctx, record("inner committed"))
+					OnRollback(ctx, record("inner rolled back"))
+					return tt.innerErr
+				})
+
+				return tt.outerErr
+			})
+
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_sql_OnCommit_withoutTransaction(t *testing.T) {
+	got := []string{}
+	OnCommit(context.Background(), func(context.Context) { got = append(got, "committed") })
+	OnRollback(context.Background(), func(context.Context) { got = append(got, "rolled back") })
+
+	assert.Equal(t, []string{"committed"}, got)
+}
@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./sql/migrate/migrate.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./sql/migrate/migrate.go -destination ./tests/mock/sql/migrate/migrate.go
+//
+
+// Package mock_migrate is a generated GoMock package.
+package mock_migrate
+
+import (
+	context "context"
+	reflect "reflect"
+
+	migrate "github.com/reyhanmichiels/go-pkg/v2/sql/migrate"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Down mocks base method.
+func (m *MockInterface) Down(ctx context.Context, n int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Down", ctx, n)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Down indicates an expected call of Down.
+func (mr *MockInterfaceMockRecorder) Down(ctx, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Down", reflect.TypeOf((*MockInterface)(nil).Down), ctx, n)
+}
+
+// Goto mocks base method.
+func (m *MockInterface) Goto(ctx context.Context, version uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Goto", ctx, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Goto indicates an expected call of Goto.
+func (mr *MockInterfaceMockRecorder) Goto(ctx, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Goto", reflect.TypeOf((*MockInterface)(nil).Goto), ctx, version)
+}
+
+// Status mocks base method.
+func (m *MockInterface) Status(ctx context.Context) ([]migrate.Status, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx)
+	ret0, _ := ret[0].([]migrate.Status)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockInterfaceMockRecorder) Status(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockInterface)(nil).Status), ctx)
+}
+
+// Up mocks base method.
+func (m *MockInterface) Up(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Up", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Up indicates an expected call of Up.
+func (mr *MockInterfaceMockRecorder) Up(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Up", reflect.TypeOf((*MockInterface)(nil).Up), ctx)
+}
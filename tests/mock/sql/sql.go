@@ -10,9 +10,11 @@
 package mock_sql
 
 import (
+	context "context"
+	sql "database/sql"
 	reflect "reflect"
 
-	sql "github.com/reyhanmichiels/go-pkg/v2/sql"
+	sql0 "github.com/reyhanmichiels/go-pkg/v2/sql"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,11 +41,60 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 	return m.recorder
 }
 
+// BulkInsert mocks base method.
+func (m *MockInterface) BulkInsert(ctx context.Context, name, table string, rows any) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkInsert", ctx, name, table, rows)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkInsert indicates an expected call of BulkInsert.
+func (mr *MockInterfaceMockRecorder) BulkInsert(ctx, name, table, rows any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkInsert", reflect.TypeOf((*MockInterface)(nil).BulkInsert), ctx, name, table, rows)
+}
+
+// Driver mocks base method.
+func (m *MockInterface) Driver() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Driver")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Driver indicates an expected call of Driver.
+func (mr *MockInterfaceMockRecorder) Driver() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Driver", reflect.TypeOf((*MockInterface)(nil).Driver))
+}
+
+// Exec mocks base method.
+func (m *MockInterface) Exec(ctx context.Context, name, query string, args ...any) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, name, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Exec", varargs...)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exec indicates an expected call of Exec.
+func (mr *MockInterfaceMockRecorder) Exec(ctx, name, query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, name, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockInterface)(nil).Exec), varargs...)
+}
+
 // Follower mocks base method.
-func (m *MockInterface) Follower() sql.Command {
+func (m *MockInterface) Follower() sql0.Command {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Follower")
-	ret0, _ := ret[0].(sql.Command)
+	ret0, _ := ret[0].(sql0.Command)
 	return ret0
 }
 
@@ -53,11 +104,44 @@ func (mr *MockInterfaceMockRecorder) Follower() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follower", reflect.TypeOf((*MockInterface)(nil).Follower))
 }
 
+// Get mocks base method.
+func (m *MockInterface) Get(ctx context.Context, name, query string, dest any, args ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, name, query, dest}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Get", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockInterfaceMockRecorder) Get(ctx, name, query, dest any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, name, query, dest}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInterface)(nil).Get), varargs...)
+}
+
+// Health mocks base method.
+func (m *MockInterface) Health() sql0.HealthStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health")
+	ret0, _ := ret[0].(sql0.HealthStatus)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockInterfaceMockRecorder) Health() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockInterface)(nil).Health))
+}
+
 // Leader mocks base method.
-func (m *MockInterface) Leader() sql.Command {
+func (m *MockInterface) Leader() sql0.Command {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Leader")
-	ret0, _ := ret[0].(sql.Command)
+	ret0, _ := ret[0].(sql0.Command)
 	return ret0
 }
 
@@ -67,6 +151,138 @@ func (mr *MockInterfaceMockRecorder) Leader() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Leader", reflect.TypeOf((*MockInterface)(nil).Leader))
 }
 
+// NamedExec mocks base method.
+func (m *MockInterface) NamedExec(ctx context.Context, name, query string, args any) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NamedExec", ctx, name, query, args)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NamedExec indicates an expected call of NamedExec.
+func (mr *MockInterfaceMockRecorder) NamedExec(ctx, name, query, args any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NamedExec", reflect.TypeOf((*MockInterface)(nil).NamedExec), ctx, name, query, args)
+}
+
+// Prepare mocks base method.
+func (m *MockInterface) Prepare(ctx context.Context, name, query string) (sql0.CommandStmt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prepare", ctx, name, query)
+	ret0, _ := ret[0].(sql0.CommandStmt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prepare indicates an expected call of Prepare.
+func (mr *MockInterfaceMockRecorder) Prepare(ctx, name, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prepare", reflect.TypeOf((*MockInterface)(nil).Prepare), ctx, name, query)
+}
+
+// PrepareNamed mocks base method.
+func (m *MockInterface) PrepareNamed(ctx context.Context, name, query string) (sql0.NamedCommandStmt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrepareNamed", ctx, name, query)
+	ret0, _ := ret[0].(sql0.NamedCommandStmt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PrepareNamed indicates an expected call of PrepareNamed.
+func (mr *MockInterfaceMockRecorder) PrepareNamed(ctx, name, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareNamed", reflect.TypeOf((*MockInterface)(nil).PrepareNamed), ctx, name, query)
+}
+
+// Query mocks base method.
+func (m *MockInterface) Query(ctx context.Context, name, query string, args ...any) (*sql0.Rows, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, name, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Query", varargs...)
+	ret0, _ := ret[0].(*sql0.Rows)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockInterfaceMockRecorder) Query(ctx, name, query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, name, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockInterface)(nil).Query), varargs...)
+}
+
+// QueryRow mocks base method.
+func (m *MockInterface) QueryRow(ctx context.Context, name, query string, args ...any) (*sql0.Row, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, name, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryRow", varargs...)
+	ret0, _ := ret[0].(*sql0.Row)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryRow indicates an expected call of QueryRow.
+func (mr *MockInterfaceMockRecorder) QueryRow(ctx, name, query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, name, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRow", reflect.TypeOf((*MockInterface)(nil).QueryRow), varargs...)
+}
+
+// Rebind mocks base method.
+func (m *MockInterface) Rebind(query string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rebind", query)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Rebind indicates an expected call of Rebind.
+func (mr *MockInterfaceMockRecorder) Rebind(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rebind", reflect.TypeOf((*MockInterface)(nil).Rebind), query)
+}
+
+// Select mocks base method.
+func (m *MockInterface) Select(ctx context.Context, name, query string, dest any, args ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, name, query, dest}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Select", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Select indicates an expected call of Select.
+func (mr *MockInterfaceMockRecorder) Select(ctx, name, query, dest any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, name, query, dest}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Select", reflect.TypeOf((*MockInterface)(nil).Select), varargs...)
+}
+
+// Stats mocks base method.
+func (m *MockInterface) Stats() sql0.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(sql0.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockInterfaceMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockInterface)(nil).Stats))
+}
+
 // Stop mocks base method.
 func (m *MockInterface) Stop() {
 	m.ctrl.T.Helper()
@@ -78,3 +294,32 @@ func (mr *MockInterfaceMockRecorder) Stop() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockInterface)(nil).Stop))
 }
+
+// Transaction mocks base method.
+func (m *MockInterface) Transaction(ctx context.Context, name string, txOpts sql0.TxOptions, f func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", ctx, name, txOpts, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockInterfaceMockRecorder) Transaction(ctx, name, txOpts, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockInterface)(nil).Transaction), ctx, name, txOpts, f)
+}
+
+// Upsert mocks base method.
+func (m *MockInterface) Upsert(ctx context.Context, name, table string, rows any, opt sql0.UpsertOptions) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, name, table, rows, opt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockInterfaceMockRecorder) Upsert(ctx, name, table, rows, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockInterface)(nil).Upsert), ctx, name, table, rows, opt)
+}